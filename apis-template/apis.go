@@ -1,17 +1,25 @@
 package apisTemplate
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"os"
+	"path/filepath"
+	"time"
 )
 
 // APIRequest 定义单个 API 请求的结构
 type APIRequest struct {
 	URL          string                 `json:"url"`                     // API 请求的 URL
 	Method       string                 `json:"method"`                  // HTTP 方法 (GET, POST, etc.)
-	Data         map[string]interface{} `json:"data,omitempty"`          // 请求体数据
+	Query        map[string]interface{} `json:"query,omitempty"`         // 查询参数，发送时经 URL 编码后拼接到 URL
+	BodyType     string                 `json:"body_type,omitempty"`     // 请求体类型: "json"（默认）, "form", "multipart", "raw", "xml"
+	Data         map[string]interface{} `json:"data,omitempty"`          // 请求体数据（json / form / multipart 的文本字段）
+	Body         string                 `json:"body,omitempty"`          // 原始请求体（raw / xml）
+	Files        map[string]string      `json:"files,omitempty"`         // multipart 上传文件: 字段名 -> 文件路径
 	Headers      map[string]string      `json:"headers,omitempty"`       // 请求头
+	Auth         string                 `json:"auth,omitempty"`          // 引用的认证配置名称（配置文件 auth_profiles 中定义）
 	SaveResponse map[string]string      `json:"save_response,omitempty"` // 保存响应中的字段
 }
 
@@ -39,10 +47,41 @@ func LoadAPITemplates(filePath string) (APITemplates, error) {
 	return templates, nil
 }
 
+// SaveAPITemplates 将模板写入 JSON 文件（4 空格缩进，模板名按字母排序）
+func SaveAPITemplates(filePath string, templates APITemplates) error {
+	return writeJSONFile(filePath, templates)
+}
+
+// SaveTestCases 将 API 用例写入 JSON 文件，格式与测试用例文件一致
+func SaveTestCases(filePath string, cases []TestCase) error {
+	return writeJSONFile(filePath, cases)
+}
+
+func writeJSONFile(filePath string, value interface{}) error {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "    ")
+	if err := encoder.Encode(value); err != nil {
+		return fmt.Errorf("序列化失败: %v", err)
+	}
+	if dir := filepath.Dir(filePath); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("创建目录失败: %v", err)
+		}
+	}
+	if err := os.WriteFile(filePath, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("写入文件失败: %v", err)
+	}
+	return nil
+}
+
 // 定义用于解析测试用例 JSON 的辅助结构体
 type TestCaseConfig struct {
-	Template string  `json:"template"`
-	Params   []Param `json:"params"`
+	Template string      `json:"template"`
+	Params   []Param     `json:"params"`
+	Auth     string      `json:"auth,omitempty"` // 覆盖模板中的 auth，以其他身份发送请求
+	Poll     *PollConfig `json:"poll,omitempty"` // 轮询直到响应满足条件，用于异步操作
 }
 
 // TestCase 对应 JSON Array 中的单个对象
@@ -54,18 +93,36 @@ type TestCase struct {
 
 // ExpectConfig 定义期望结果
 type ExpectConfig struct {
-	Status int                    `json:"status"`
-	Body   map[string]interface{} `json:"body"`
+	Status        int                     `json:"status"`                    // 期望的状态码，0 表示不校验
+	StatusIn      []string                `json:"-"`                         // status 为范围或数组时的匹配规则，如 "2xx"、"201"
+	Headers       map[string]HeaderExpect `json:"headers,omitempty"`         // 响应头断言
+	MaxDurationMs int                     `json:"max_duration_ms,omitempty"` // 请求耗时上限（毫秒）
+	RawContains   string                  `json:"raw_contains,omitempty"`    // 原始响应文本需包含的内容
+	RawRegex      string                  `json:"raw_regex,omitempty"`       // 原始响应文本需匹配的正则
+	Body          map[string]interface{}  `json:"body,omitempty"`
+	Assertions    []Assertion             `json:"assertions,omitempty"` // JSONPath 断言，作用于完整的响应 JSON
+	Schema        json.RawMessage         `json:"schema,omitempty"`     // JSON Schema（draft-07），内联对象或 schema 文件路径
 }
 
 // ValidateResponse 验证响应是否符合期望
 func ValidateResponse(resp *APIResponse, expect ExpectConfig) error {
 	// 1. 校验状态码
-	if resp.StatusCode != expect.Status {
-		return fmt.Errorf("HTTP状态码不匹配: 期望 %d, 实际 %d", expect.Status, resp.StatusCode)
+	if err := expect.checkStatus(resp.StatusCode); err != nil {
+		return err
+	}
+
+	// 2. 校验耗时、响应头与原始响应文本
+	if expect.MaxDurationMs > 0 && resp.Duration > time.Duration(expect.MaxDurationMs)*time.Millisecond {
+		return fmt.Errorf("请求耗时超出上限: 期望不超过 %dms, 实际 %dms", expect.MaxDurationMs, resp.Duration.Milliseconds())
+	}
+	if err := expect.checkHeaders(resp.Header); err != nil {
+		return err
+	}
+	if err := expect.checkRawBody(resp.RawBody); err != nil {
+		return err
 	}
 
-	// 2. 校验 Body
+	// 3. 校验 Body
 	for key, expectedVal := range expect.Body {
 		actualVal, exists := resp.Body[key]
 		if !exists {
@@ -76,6 +133,32 @@ func ValidateResponse(resp *APIResponse, expect ExpectConfig) error {
 			return fmt.Errorf("字段 '%s' 校验失败: 期望 %v, 实际 %v", key, expectedVal, actualVal)
 		}
 	}
+
+	// 4. JSONPath 断言
+	if len(expect.Assertions) > 0 {
+		document, err := resp.document()
+		if err != nil {
+			return fmt.Errorf("JSONPath 断言失败: %v", err)
+		}
+		if err := EvaluateAssertions(document, expect.Assertions); err != nil {
+			return err
+		}
+	}
+
+	// 5. JSON Schema 校验，列出所有违规位置
+	if len(expect.Schema) > 0 {
+		document, err := resp.document()
+		if err != nil {
+			return fmt.Errorf("JSON Schema 校验失败: %v", err)
+		}
+		violations, err := ValidateSchema(expect.Schema, document)
+		if err != nil {
+			return err
+		}
+		if len(violations) > 0 {
+			return fmt.Errorf("响应不符合 JSON Schema (%d 处): %s", len(violations), formatViolations(violations))
+		}
+	}
 	return nil
 }
 
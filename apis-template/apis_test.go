@@ -2,8 +2,16 @@ package apisTemplate
 
 import (
 	"encoding/json"
+	"encoding/pem"
 	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestLoadAPITemplates(t *testing.T) {
@@ -57,6 +65,12 @@ func TestGenerateRequest_ParamReplacement(t *testing.T) {
 			},{
 				"key": "{password}",
 				"value": "localhost"
+			},{
+				"key": "{api_host}",
+				"value": "127.0.0.1"
+			},{
+				"key": "{api_port}",
+				"value": 8080
 			}]
 		}
 	}`
@@ -117,7 +131,9 @@ func TestGenerateRequest_URLReplacement(t *testing.T) {
 			"template": "call_login",
 			"params": [
 				{ "key": "{api_host}", "value": "192.168.1.100" },
-				{ "key": "{api_port}", "value": "8080" }
+				{ "key": "{api_port}", "value": "8080" },
+				{ "key": "{username}", "value": "admin" },
+				{ "key": "{password}", "value": "123456" }
 			]
 		}
 	}`
@@ -232,3 +248,711 @@ func TestRunTestCases_RealServer(t *testing.T) {
 		})
 	}
 }
+
+func TestGenerateRequest_HeaderReplacement(t *testing.T) {
+	tmpl := APIRequest{
+		URL:    "http://localhost/api/access_control/add",
+		Method: "post",
+		Headers: map[string]string{
+			"Authorization": "Bearer {token}",
+		},
+	}
+
+	gotReq, err := GenerateRequest(tmpl, []Param{{Key: "{token}", Value: "abc"}})
+	if err != nil {
+		t.Fatalf("GenerateRequest 出错: %v", err)
+	}
+	if gotReq.Headers["Authorization"] != "Bearer abc" {
+		t.Errorf("Header 替换错误: %s", gotReq.Headers["Authorization"])
+	}
+	if tmpl.Headers["Authorization"] != "Bearer {token}" {
+		t.Errorf("原始模板被修改: %s", tmpl.Headers["Authorization"])
+	}
+}
+
+func TestExtractSaveResponse(t *testing.T) {
+	resp := &APIResponse{StatusCode: 200}
+	resp.parseBody([]byte(`{"token": "t-123", "data": {"id": 42, "items": [{"name": "a"}, {"name": "b"}]}}`))
+
+	saved, err := ExtractSaveResponse(resp, map[string]string{
+		"token":             "response.token",
+		"access_control_id": "response.data.id",
+		"second_name":       "response.data.items[1].name",
+	})
+	if err != nil {
+		t.Fatalf("ExtractSaveResponse 出错: %v", err)
+	}
+
+	expected := map[string]string{
+		"token":             "t-123",
+		"access_control_id": "42",
+		"second_name":       "b",
+	}
+	for name, want := range expected {
+		if saved[name] != want {
+			t.Errorf("变量 %s 期望 '%s', 实际 '%s'", name, want, saved[name])
+		}
+	}
+
+	if _, err := ExtractSaveResponse(resp, map[string]string{"missing": "response.data.missing"}); err == nil {
+		t.Error("不存在的路径应返回错误")
+	}
+
+	// 非 JSON 响应不能退回到空的 Body 上取值
+	text := &APIResponse{StatusCode: 200}
+	text.parseBody([]byte("<html>ok</html>"))
+	if _, err := ExtractSaveResponse(text, map[string]string{"all": "response"}); err == nil || !strings.Contains(err.Error(), "响应不是 JSON") {
+		t.Errorf("非 JSON 响应应返回错误, 实际: %v", err)
+	}
+
+	// 响应为 null 时取到的是 null 本身
+	null := &APIResponse{StatusCode: 200}
+	null.parseBody([]byte("null"))
+	values, err := ExtractSaveResponseValues(null, map[string]string{"all": "response"})
+	if err != nil || values["all"] != nil {
+		t.Errorf("null 响应应取到 nil, 实际: %v, %v", values, err)
+	}
+}
+
+func TestValidateResponse_Assertions(t *testing.T) {
+	raw := `[{"id": 1, "name": "张三", "tags": ["admin", "ops"]}, {"id": 2, "name": "李四", "tags": []}]`
+	resp := &APIResponse{StatusCode: 200}
+	resp.parseBody([]byte(raw))
+
+	passing := []Assertion{
+		{Path: "$", Op: "len", Value: 2.0},
+		{Path: "$[0].name", Op: "eq", Value: "张三"},
+		{Path: "$[-1].id", Op: "eq", Value: 2},
+		{Path: "$[0].tags", Op: "contains", Value: "ops"},
+		{Path: "$[0]['tags']", Op: "eq", Value: []interface{}{"admin", "ops"}},
+		{Path: "$[*].name", Op: "contains", Value: "李四"},
+		{Path: "$[*].id", Op: "len", Value: 2.0},
+		{Path: "$[1].name", Op: "regex", Value: "^李"},
+		{Path: "$[1].id", Op: "gt", Value: 1},
+		{Path: "$[0].id", Op: "lt", Value: 2},
+		{Path: "$[0].name", Op: "ne", Value: "李四"},
+		{Path: "$[1].tags", Op: "exists"},
+		{Path: "$[1].email", Op: "exists", Value: false},
+	}
+	if err := ValidateResponse(resp, ExpectConfig{Status: 200, Assertions: passing}); err != nil {
+		t.Errorf("断言应全部通过: %v", err)
+	}
+
+	failing := []Assertion{
+		{Path: "$[0].name", Op: "eq", Value: "王五"},
+		{Path: "$[5].name", Op: "exists"},
+		{Path: "$[0].id", Op: "gt", Value: 10},
+	}
+	err := ValidateResponse(resp, ExpectConfig{Status: 200, Assertions: failing})
+	if err == nil {
+		t.Fatal("断言应失败")
+	}
+	for _, want := range []string{
+		`断言 $[0].name eq 失败: 期望 "王五", 实际 "张三"`,
+		"断言 $[5].name exists 失败",
+		"断言 $[0].id gt 失败: 期望 10, 实际 1",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("失败信息缺少 %q: %v", want, err)
+		}
+	}
+
+	if _, err := EvaluateJSONPath(resp.JSON, "data.items"); err == nil {
+		t.Error("不以 $ 开头的路径应返回错误")
+	}
+	// 默认按 JSON 类型比较，loose 时按文本比较
+	typed := &APIResponse{StatusCode: 200}
+	typed.parseBody([]byte(`{"id": 1, "enabled": true, "ids": [1, 2]}`))
+	for _, a := range []Assertion{
+		{Path: "$.id", Op: "eq", Value: "1"},
+		{Path: "$.enabled", Op: "eq", Value: "true"},
+		{Path: "$.ids", Op: "contains", Value: "2"},
+	} {
+		if err := a.Evaluate(typed.JSON); err == nil {
+			t.Errorf("类型不同时断言应失败: %+v", a)
+		}
+		a.Loose = true
+		if err := a.Evaluate(typed.JSON); err != nil {
+			t.Errorf("loose 时应按文本比较: %v", err)
+		}
+	}
+
+	text := &APIResponse{StatusCode: 200}
+	text.parseBody([]byte("<html>ok</html>"))
+	err = ValidateResponse(text, ExpectConfig{Status: 200, Assertions: []Assertion{{Path: "$", Op: "exists", Value: false}}})
+	if err == nil || !strings.Contains(err.Error(), "响应不是 JSON") {
+		t.Errorf("非 JSON 响应的断言应失败, 实际: %v", err)
+	}
+}
+
+func TestValidateResponse_Schema(t *testing.T) {
+	schema := json.RawMessage(`{
+		"$schema": "http://json-schema.org/draft-07/schema#",
+		"type": "object",
+		"required": ["code", "data"],
+		"properties": {
+			"code": {"type": "integer", "enum": [0, 1]},
+			"data": {
+				"type": "array",
+				"items": {"$ref": "#/definitions/user"}
+			}
+		},
+		"definitions": {
+			"user": {
+				"type": "object",
+				"required": ["id", "name"],
+				"additionalProperties": false,
+				"properties": {
+					"id": {"type": "integer", "minimum": 1},
+					"name": {"type": "string", "pattern": "^[a-z]+$"},
+					"email": {"type": ["string", "null"]}
+				}
+			}
+		}
+	}`)
+
+	valid := &APIResponse{StatusCode: 200}
+	valid.parseBody([]byte(`{"code": 0, "data": [{"id": 1, "name": "alice", "email": null}]}`))
+	if err := ValidateResponse(valid, ExpectConfig{Status: 200, Schema: schema}); err != nil {
+		t.Errorf("合法响应校验失败: %v", err)
+	}
+
+	invalid := &APIResponse{StatusCode: 200}
+	invalid.parseBody([]byte(`{"code": 2, "data": [{"id": 0, "name": "Bob", "age": 3}, {"name": "carol"}]}`))
+	violations, err := ValidateSchema(schema, invalid.JSON)
+	if err != nil {
+		t.Fatalf("ValidateSchema 出错: %v", err)
+	}
+	want := []string{
+		"/code: 值 2 不在枚举 [0,1] 中",
+		"/data/0/age: 不允许的额外字段 'age'",
+		"/data/0/id: 数值 0 小于 minimum 1",
+		`/data/0/name: 字符串 "Bob" 不匹配 pattern '^[a-z]+$'`,
+		"/data/1: 缺少必需字段 'id'",
+	}
+	if len(violations) != len(want) {
+		t.Fatalf("期望 %d 处违规, 实际 %d: %v", len(want), len(violations), violations)
+	}
+	for i, v := range violations {
+		if v.String() != want[i] {
+			t.Errorf("违规 %d 期望 %q, 实际 %q", i, want[i], v.String())
+		}
+	}
+
+	if err := ValidateResponse(invalid, ExpectConfig{Status: 200, Schema: schema}); err == nil || !strings.Contains(err.Error(), "(5 处)") {
+		t.Errorf("ValidateResponse 应报告全部违规: %v", err)
+	}
+
+	if _, err := ValidateSchema(json.RawMessage(`{"$ref": "http://example.com/schema.json"}`), valid.JSON); err == nil {
+		t.Error("外部 $ref 应返回错误")
+	}
+}
+
+func TestValidateResponse_HeadersTimingRaw(t *testing.T) {
+	var expect ExpectConfig
+	raw := `{
+		"status": ["2xx", 304],
+		"headers": {
+			"content-type": {"contains": "text/html"},
+			"X-Request-Id": {"regex": "^req-\\d+$"},
+			"Cache-Control": "no-cache"
+		},
+		"max_duration_ms": 500,
+		"raw_contains": "<title>首页</title>",
+		"raw_regex": "版本 \\d+\\.\\d+"
+	}`
+	if err := json.Unmarshal([]byte(raw), &expect); err != nil {
+		t.Fatalf("解析 expect 失败: %v", err)
+	}
+
+	resp := &APIResponse{
+		StatusCode: 204,
+		Header: http.Header{
+			"Content-Type":  {"text/html; charset=utf-8"},
+			"X-Request-Id":  {"req-42"},
+			"Cache-Control": {"no-cache"},
+		},
+		RawBody:  "<html><title>首页</title>版本 1.2</html>",
+		Duration: 120 * time.Millisecond,
+	}
+	if err := ValidateResponse(resp, expect); err != nil {
+		t.Fatalf("校验应通过: %v", err)
+	}
+
+	checks := []struct {
+		modify func(r *APIResponse)
+		want   string
+	}{
+		{func(r *APIResponse) { r.StatusCode = 404 }, "期望 2xx / 304, 实际 404"},
+		{func(r *APIResponse) { r.Duration = 800 * time.Millisecond }, "请求耗时超出上限"},
+		{func(r *APIResponse) { r.Header.Set("X-Request-Id", "abc") }, "响应头 'X-Request-Id' 校验失败"},
+		{func(r *APIResponse) { r.Header.Del("Cache-Control") }, "响应头缺少: Cache-Control"},
+		{func(r *APIResponse) { r.RawBody = "<title>首页</title>" }, "期望匹配"},
+	}
+	for _, check := range checks {
+		copied := *resp
+		copied.Header = resp.Header.Clone()
+		check.modify(&copied)
+		err := ValidateResponse(&copied, expect)
+		if err == nil || !strings.Contains(err.Error(), check.want) {
+			t.Errorf("期望错误包含 %q, 实际: %v", check.want, err)
+		}
+	}
+
+	var single ExpectConfig
+	if err := json.Unmarshal([]byte(`{"status": 201}`), &single); err != nil || single.Status != 201 {
+		t.Errorf("数字 status 解析错误: %+v, %v", single, err)
+	}
+	if err := json.Unmarshal([]byte(`{"status": "20"}`), &single); err == nil {
+		t.Error("无效的 status 应返回错误")
+	}
+}
+
+func TestExecuteRequest_BodyTypes(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		result := map[string]interface{}{"content_type": req.Header.Get("Content-Type")}
+		switch {
+		case strings.HasPrefix(req.Header.Get("Content-Type"), "multipart/"):
+			if err := req.ParseMultipartForm(1 << 20); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			result["name"] = req.FormValue("name")
+			file, header, err := req.FormFile("upload")
+			if err == nil {
+				content, _ := io.ReadAll(file)
+				result["file"] = header.Filename + ":" + string(content)
+			}
+		case req.Header.Get("Content-Type") == "application/x-www-form-urlencoded":
+			req.ParseForm()
+			result["form"] = req.PostForm.Encode()
+		default:
+			body, _ := io.ReadAll(req.Body)
+			result["raw"] = string(body)
+		}
+		json.NewEncoder(w).Encode(result)
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "users.csv"), []byte("id,name"), 0644)
+	params := []Param{{Key: "{dir}", Value: dir}, {Key: "{user}", Value: "admin"}}
+
+	cases := []struct {
+		template APIRequest
+		want     map[string]string
+	}{
+		{
+			APIRequest{BodyType: "form", Data: map[string]interface{}{"username": "{user}", "role": []interface{}{"a", "b"}}},
+			map[string]string{"content_type": "application/x-www-form-urlencoded", "form": "role=a&role=b&username=admin"},
+		},
+		{
+			APIRequest{BodyType: "multipart", Data: map[string]interface{}{"name": "{user}"}, Files: map[string]string{"upload": "{dir}/users.csv"}},
+			map[string]string{"name": "admin", "file": "users.csv:id,name"},
+		},
+		{
+			APIRequest{BodyType: "xml", Body: "<user>{user}</user>"},
+			map[string]string{"content_type": "application/xml; charset=utf-8", "raw": "<user>admin</user>"},
+		},
+		{
+			APIRequest{BodyType: "raw", Body: "hello {user}", Headers: map[string]string{"content-type": "text/csv"}},
+			map[string]string{"content_type": "text/csv", "raw": "hello admin"},
+		},
+		{
+			APIRequest{Data: map[string]interface{}{"user": "{user}"}},
+			map[string]string{"content_type": "application/json", "raw": `{"user":"admin"}`},
+		},
+	}
+	for _, c := range cases {
+		c.template.URL = server.URL
+		c.template.Method = "post"
+		req, err := GenerateRequest(c.template, params)
+		if err != nil {
+			t.Fatalf("GenerateRequest 出错: %v", err)
+		}
+		resp, err := ExecuteRequest(req)
+		if err != nil {
+			t.Fatalf("[%s] 请求失败: %v", c.template.BodyType, err)
+		}
+		for key, want := range c.want {
+			if got := stringifyValue(resp.Body[key]); strings.TrimSpace(got) != want {
+				t.Errorf("[%s] %s 期望 %q, 实际 %q", c.template.BodyType, key, want, got)
+			}
+		}
+	}
+
+	if _, err := ExecuteRequest(APIRequest{URL: server.URL, Method: "post", BodyType: "yaml"}); err == nil {
+		t.Error("不支持的 body_type 应返回错误")
+	}
+	if _, err := ExecuteRequest(APIRequest{URL: server.URL, Method: "post", Files: map[string]string{"f": "a.txt"}}); err == nil {
+		t.Error("非 multipart 请求使用 files 应返回错误")
+	}
+}
+
+func TestGenerateRequest_QueryTypedAndUnresolved(t *testing.T) {
+	var params []Param
+	if err := json.Unmarshal([]byte(`[
+		{"key": "{page_size}", "value": 20},
+		{"key": "{active}", "value": true},
+		{"key": "{filter}", "value": {"role": "admin"}},
+		{"key": "{keyword}", "value": "张 三&co"},
+		{"key": "{id}", "value": "a/b c"}
+	]`), &params); err != nil {
+		t.Fatalf("参数解析失败: %v", err)
+	}
+
+	template := APIRequest{
+		URL:    "http://localhost:8080/api/users/{id}?from={keyword}",
+		Method: "get",
+		Query: map[string]interface{}{
+			"size":    "{page_size}",
+			"q":       "{keyword}",
+			"tags":    []interface{}{"a", "b"},
+			"caption": "size={page_size}",
+		},
+		Data: map[string]interface{}{
+			"page_size": "{page_size}",
+			"active":    "{active}",
+			"filter":    "{filter}",
+			"label":     "每页 {page_size} 条",
+		},
+	}
+	req, err := GenerateRequest(template, params)
+	if err != nil {
+		t.Fatalf("GenerateRequest 出错: %v", err)
+	}
+
+	if req.Data["page_size"] != float64(20) || req.Data["active"] != true {
+		t.Errorf("独占占位符应保留参数类型, 实际 %#v", req.Data)
+	}
+	if filter, ok := req.Data["filter"].(map[string]interface{}); !ok || filter["role"] != "admin" {
+		t.Errorf("对象参数应保留类型, 实际 %#v", req.Data["filter"])
+	}
+	if req.Data["label"] != "每页 20 条" {
+		t.Errorf("嵌入字符串中的占位符应按文本替换, 实际 %#v", req.Data["label"])
+	}
+
+	wantURL := "http://localhost:8080/api/users/a/b%20c?from=%E5%BC%A0+%E4%B8%89%26co" +
+		"&caption=size%3D20&q=%E5%BC%A0+%E4%B8%89%26co&size=20&tags=a&tags=b"
+	if got := BuildURL(req); got != wantURL {
+		t.Errorf("URL 编码错误.\n期望: %s\n实际: %s", wantURL, got)
+	}
+
+	template.Headers = map[string]string{"Authorization": "Bearer {token}"}
+	template.Data["owner"] = "{owner_id}"
+	_, err = GenerateRequest(template, params)
+	if err == nil || !strings.Contains(err.Error(), "{owner_id}, {token}") {
+		t.Errorf("未解析的占位符应返回错误并列出名称, 实际 %v", err)
+	}
+}
+
+func TestClient_SessionTLSRedirectProxy(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/login", func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "sid", Value: "abc", Path: "/"})
+		w.Write([]byte(`{}`))
+	})
+	mux.HandleFunc("/me", func(w http.ResponseWriter, r *http.Request) {
+		sid := ""
+		if cookie, err := r.Cookie("sid"); err == nil {
+			sid = cookie.Value
+		}
+		w.Write([]byte(`{"sid":"` + sid + `"}`))
+	})
+	mux.HandleFunc("/old", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/me", http.StatusFound)
+	})
+	server := httptest.NewTLSServer(mux)
+	defer server.Close()
+
+	// 默认校验证书，自签名证书应失败
+	strict, err := NewClient(ClientConfig{})
+	if err != nil {
+		t.Fatalf("NewClient 出错: %v", err)
+	}
+	if _, err := strict.Execute(APIRequest{URL: server.URL + "/me", Method: "get"}); err == nil {
+		t.Error("未信任的自签名证书应请求失败")
+	}
+
+	// ca_file 信任服务端证书
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(caFile, certPEM, 0644); err != nil {
+		t.Fatal(err)
+	}
+	client, err := NewClient(ClientConfig{CAFile: caFile})
+	if err != nil {
+		t.Fatalf("NewClient 出错: %v", err)
+	}
+
+	// 同一会话内共享 Cookie，新会话不携带
+	if _, err := client.Execute(APIRequest{URL: server.URL + "/login", Method: "post"}); err != nil {
+		t.Fatalf("登录请求失败: %v", err)
+	}
+	resp, err := client.Execute(APIRequest{URL: server.URL + "/me", Method: "get"})
+	if err != nil || resp.Body["sid"] != "abc" {
+		t.Errorf("同一会话应携带 Cookie, 实际 %v (%v)", resp, err)
+	}
+	resp, err = client.NewSession().Execute(APIRequest{URL: server.URL + "/me", Method: "get"})
+	if err != nil || resp.Body["sid"] != "" {
+		t.Errorf("新会话不应携带 Cookie, 实际 %v (%v)", resp, err)
+	}
+
+	// 重定向策略
+	resp, err = client.Execute(APIRequest{URL: server.URL + "/old", Method: "get"})
+	if err != nil || resp.StatusCode != http.StatusOK {
+		t.Errorf("默认应跟随重定向, 实际 %v (%v)", resp, err)
+	}
+	noFollow := false
+	insecure := true
+	manual, err := NewClient(ClientConfig{InsecureSkipVerify: &insecure, FollowRedirects: &noFollow})
+	if err != nil {
+		t.Fatalf("NewClient 出错: %v", err)
+	}
+	resp, err = manual.Execute(APIRequest{URL: server.URL + "/old", Method: "get"})
+	if err != nil || resp.StatusCode != http.StatusFound || resp.Header.Get("Location") != "/me" {
+		t.Errorf("follow_redirects: false 应返回 302, 实际 %v (%v)", resp, err)
+	}
+
+	// 代理
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"proxied":"` + r.URL.String() + `"}`))
+	}))
+	defer proxy.Close()
+	proxied, err := NewClient(ClientConfig{Proxy: proxy.URL})
+	if err != nil {
+		t.Fatalf("NewClient 出错: %v", err)
+	}
+	resp, err = proxied.Execute(APIRequest{URL: "http://device.invalid/api/status", Method: "get"})
+	if err != nil || resp.Body["proxied"] != "http://device.invalid/api/status" {
+		t.Errorf("请求应经过代理, 实际 %v (%v)", resp, err)
+	}
+
+	if _, err := NewClient(ClientConfig{ClientCert: "client.pem"}); err == nil {
+		t.Error("只配置 client_cert 应返回错误")
+	}
+}
+
+func TestImportOpenAPI(t *testing.T) {
+	spec := `
+openapi: 3.0.3
+servers:
+  - url: https://{host}/api/v1
+    variables:
+      host: {default: device.local}
+security:
+  - bearerAuth: []
+components:
+  securitySchemes:
+    bearerAuth: {type: http, scheme: bearer}
+  schemas:
+    User:
+      type: object
+      properties:
+        name: {type: string, example: admin}
+        role: {type: string, enum: [viewer, editor]}
+        age: {type: integer}
+        tags: {type: array, items: {type: string, default: ops}}
+paths:
+  /users/{id}:
+    parameters:
+      - {name: id, in: path, required: true, schema: {type: integer, example: 42}}
+    get:
+      operationId: getUser
+      summary: 查询用户
+      parameters:
+        - {name: verbose, in: query, schema: {type: boolean, default: false}}
+        - {name: fields, in: query, required: true, schema: {type: string}}
+        - {name: cursor, in: query, schema: {type: string}}
+      responses:
+        "200": {description: ok}
+        "404": {description: missing}
+    put:
+      operationId: updateUser
+      requestBody:
+        content:
+          application/json:
+            schema: {$ref: "#/components/schemas/User"}
+      responses:
+        "204": {description: updated}
+  /ping:
+    get:
+      security: []
+      responses:
+        default: {description: ok}
+`
+	result, err := ImportOpenAPI([]byte(spec))
+	if err != nil {
+		t.Fatalf("ImportOpenAPI 出错: %v", err)
+	}
+
+	getUser := result.Templates["getUser"]
+	if getUser.URL != "{base_url}/api/v1/users/{id}" || getUser.Method != "get" {
+		t.Errorf("URL 或方法错误: %s %s", getUser.Method, getUser.URL)
+	}
+	if getUser.Query["fields"] != "{fields}" || getUser.Query["verbose"] != "{verbose}" || getUser.Query["cursor"] != "{cursor}" {
+		t.Errorf("查询参数转换错误: %#v", getUser.Query)
+	}
+	if getUser.Headers["Authorization"] != "Bearer {token}" {
+		t.Errorf("bearer 认证应转换为请求头: %#v", getUser.Headers)
+	}
+
+	updateUser := result.Templates["updateUser"]
+	wantData := map[string]interface{}{"name": "admin", "role": "viewer", "age": float64(0), "tags": []interface{}{"ops"}}
+	if !valuesEqual(wantData, updateUser.Data, false) {
+		t.Errorf("请求体应由 schema 示例生成: %#v", updateUser.Data)
+	}
+	ping, exists := result.Templates["get_ping"]
+	if !exists || ping.Headers["Authorization"] != "" {
+		t.Errorf("缺少 operationId 时应由方法与路径命名，security: [] 不加认证: %#v", result.Templates)
+	}
+
+	// 按路径排序: /ping, /users/{id} (get, put)
+	if len(result.Cases) != 3 || result.Cases[1].Name != "查询用户" || result.Cases[1].Expect.Status != 200 {
+		t.Fatalf("骨架用例错误: %#v", result.Cases)
+	}
+	if result.Cases[2].Expect.Status != 204 || len(result.Cases[0].Expect.StatusIn) != 1 {
+		t.Errorf("骨架用例应断言文档中的成功状态码: %#v", result.Cases)
+	}
+	params := map[string]string{}
+	for _, p := range result.Cases[1].APIConfig.Params {
+		params[p.Key] = p.Value
+	}
+	// {token} 来自运行期变量，不写入参数
+	if params["{base_url}"] != "https://device.local" || params["{id}"] != "42" || params["{verbose}"] != "false" ||
+		params["{cursor}"] != "" || len(params) != 5 {
+		t.Errorf("骨架用例参数错误: %#v", params)
+	}
+
+	// 骨架用例序列化后可以重新解析
+	path := filepath.Join(t.TempDir(), "cases.json")
+	if err := SaveTestCases(path, result.Cases); err != nil {
+		t.Fatalf("SaveTestCases 出错: %v", err)
+	}
+	var cases []TestCase
+	content, _ := os.ReadFile(path)
+	if err := json.Unmarshal(content, &cases); err != nil || cases[0].Expect.StatusIn[0] != "2xx" {
+		t.Errorf("骨架用例无法重新解析: %v\n%s", err, content)
+	}
+
+	swagger := `{
+		"swagger": "2.0", "host": "10.0.0.1:8080", "basePath": "/v2", "schemes": ["http"],
+		"securityDefinitions": {"key": {"type": "apiKey", "in": "header", "name": "X-API-Key"}},
+		"security": [{"key": []}],
+		"paths": {"/upload": {"post": {"operationId": "upload", "consumes": ["multipart/form-data"],
+			"parameters": [{"name": "file", "in": "formData", "type": "file"}, {"name": "note", "in": "formData", "type": "string"}],
+			"responses": {"201": {"description": "created"}}}}}
+	}`
+	result, err = ImportOpenAPI([]byte(swagger))
+	if err != nil {
+		t.Fatalf("导入 Swagger 2.0 出错: %v", err)
+	}
+	upload := result.Templates["upload"]
+	if upload.URL != "{base_url}/v2/upload" || upload.BodyType != BodyTypeMultipart || upload.Files["file"] != "{file}" ||
+		upload.Headers["X-API-Key"] != "{x_api_key}" {
+		t.Errorf("Swagger 2.0 转换错误: %#v", upload)
+	}
+	if result.Cases[0].APIConfig.Params[0].Value != "http://10.0.0.1:8080" {
+		t.Errorf("base_url 应取自 host: %#v", result.Cases[0].APIConfig.Params)
+	}
+}
+
+func TestImportPostmanCurlAndMerge(t *testing.T) {
+	collection := `{
+		"info": {"name": "设备", "schema": "https://schema.getpostman.com/json/collection/v2.1.0/collection.json"},
+		"auth": {"type": "bearer", "bearer": [{"key": "token", "value": "{{token}}"}]},
+		"item": [
+			{"name": "Users", "item": [
+				{"name": "Get User", "request": {"method": "GET",
+					"header": [{"key": "X-Trace", "value": "{{trace}}"}, {"key": "X-Off", "value": "1", "disabled": true}],
+					"url": {"raw": "{{base_url}}/users/:id?verbose=true", "query": [{"key": "verbose", "value": "true"}]}}},
+				{"name": "Create User", "request": {"method": "POST",
+					"body": {"mode": "raw", "raw": "{\"name\": \"{{name}}\", \"age\": {{age}}}", "options": {"raw": {"language": "json"}}},
+					"url": "{{base_url}}/users"}}
+			]},
+			{"name": "Login", "request": {"method": "POST",
+				"auth": {"type": "basic", "basic": [{"key": "username", "value": "admin"}, {"key": "password", "value": "secret"}]},
+				"body": {"mode": "urlencoded", "urlencoded": [{"key": "remember", "value": "1"}]},
+				"url": "{{base_url}}/login"}}
+		]
+	}`
+	result, err := ImportPostman([]byte(collection))
+	if err != nil {
+		t.Fatalf("ImportPostman 出错: %v", err)
+	}
+	getUser := result.Templates["users.get_user"]
+	if getUser.URL != "{base_url}/users/{id}" || getUser.Query["verbose"] != "true" || getUser.Headers["X-Trace"] != "{trace}" ||
+		getUser.Headers["X-Off"] != "" || getUser.Headers["Authorization"] != "Bearer {token}" {
+		t.Errorf("文件夹前缀、变量或继承的认证转换错误: %#v", getUser)
+	}
+	createUser := result.Templates["users.create_user"]
+	if createUser.Data["name"] != "{name}" || createUser.Data["age"] != "{age}" {
+		t.Errorf("JSON 请求体中的变量应转换为占位符: %#v", createUser.Data)
+	}
+	login := result.Templates["login"]
+	if login.BodyType != BodyTypeForm || login.Data["remember"] != "1" || login.Headers["Authorization"] != "Basic YWRtaW46c2VjcmV0" {
+		t.Errorf("表单或 basic 认证转换错误: %#v", login)
+	}
+
+	command := `curl 'https://device.local/api/items?page=1' \
+  -X PUT -H 'Content-Type: application/json' -H "Authorization: Bearer abc" \
+  --data-raw '{"enabled": true}' --compressed -k`
+	result, err = ImportCurl(command, "")
+	if err != nil {
+		t.Fatalf("ImportCurl 出错: %v", err)
+	}
+	item, exists := result.Templates["put_api_items"]
+	if !exists || item.URL != "https://device.local/api/items" || item.Query["page"] != "1" ||
+		item.Data["enabled"] != true || item.Headers["Authorization"] != "Bearer abc" {
+		t.Errorf("curl 转换错误: %#v", result.Templates)
+	}
+	result, err = ImportCurl(`curl -u admin:secret -F file=@fw.bin -F note=v2 http://10.0.0.1/upload`, "upload")
+	if err != nil {
+		t.Fatalf("ImportCurl 出错: %v", err)
+	}
+	upload := result.Templates["upload"]
+	if upload.Method != "post" || upload.BodyType != BodyTypeMultipart || upload.Files["file"] != "fw.bin" ||
+		upload.Data["note"] != "v2" || upload.Headers["Authorization"] != "Basic YWRtaW46c2VjcmV0" {
+		t.Errorf("curl multipart 转换错误: %#v", upload)
+	}
+
+	// 合并：已存在的模板默认跳过，force 时覆盖
+	path := filepath.Join(t.TempDir(), "apis.json")
+	if err := SaveAPITemplates(path, APITemplates{"upload": {URL: "http://old", Method: "post"}}); err != nil {
+		t.Fatalf("SaveAPITemplates 出错: %v", err)
+	}
+	imported := APITemplates{"upload": upload, "login": login}
+	merged, err := MergeAPITemplates(path, imported, false)
+	if err != nil || len(merged.Added) != 1 || len(merged.Skipped) != 1 {
+		t.Fatalf("合并结果错误: %+v, %v", merged, err)
+	}
+	templates, _ := LoadAPITemplates(path)
+	if templates["upload"].URL != "http://old" || templates["login"].URL != "{base_url}/login" {
+		t.Errorf("未指定 force 时不应覆盖已有模板: %#v", templates)
+	}
+	merged, err = MergeAPITemplates(path, imported, true)
+	if err != nil || len(merged.Replaced) != 2 {
+		t.Fatalf("force 合并结果错误: %+v, %v", merged, err)
+	}
+	templates, _ = LoadAPITemplates(path)
+	if templates["upload"].URL != "http://10.0.0.1/upload" {
+		t.Errorf("force 时应覆盖已有模板: %#v", templates["upload"])
+	}
+
+	// 合并只修改新增和覆盖的模板，其余模板的顺序、格式与未知字段保持原样
+	kept := `{"url": "http://{host}/status",  "method": "get", "comment": "手写说明"}`
+	os.WriteFile(path, []byte("{\n  \"zeta\": "+kept+",\n  \"upload\": {\"url\": \"http://old\"}\n}\n"), 0644)
+	if _, err := MergeAPITemplates(path, imported, true); err != nil {
+		t.Fatalf("合并出错: %v", err)
+	}
+	content, _ := os.ReadFile(path)
+	if !strings.HasPrefix(string(content), "{\n  \"zeta\": "+kept+",\n  \"upload\": {\n") {
+		t.Errorf("未修改的模板应保持原样:\n%s", content)
+	}
+	if !strings.Contains(string(content), `"login": {`) || strings.Contains(string(content), "http://old") {
+		t.Errorf("新增或覆盖的模板不正确:\n%s", content)
+	}
+	if templates, err = LoadAPITemplates(path); err != nil || templates["login"].URL != "{base_url}/login" {
+		t.Errorf("合并后的文件无法解析: %v", err)
+	}
+}
@@ -0,0 +1,203 @@
+package apisTemplate
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Assertion 基于 JSONPath 的响应断言
+// 例如 {"path": "$.data.items[0].name", "op": "eq", "value": "张三"}
+type Assertion struct {
+	Path  string      `json:"path"`            // JSONPath 表达式，根节点可以是对象或数组
+	Op    string      `json:"op"`              // "eq", "ne", "contains", "regex", "gt", "lt", "exists", "len"
+	Value interface{} `json:"value,omitempty"` // 期望值；exists 时为 true/false（默认 true）
+	Loose bool        `json:"loose,omitempty"` // eq / ne / contains 按文本比较，如 "1" 与 1、"true" 与 true 视为相等
+}
+
+// EvaluateAssertions 依次计算所有断言，返回全部失败信息（以 "; " 分隔）
+func EvaluateAssertions(document interface{}, assertions []Assertion) error {
+	var failures []string
+	for _, assertion := range assertions {
+		if err := assertion.Evaluate(document); err != nil {
+			failures = append(failures, err.Error())
+		}
+	}
+	if len(failures) > 0 {
+		return fmt.Errorf("%s", strings.Join(failures, "; "))
+	}
+	return nil
+}
+
+// Evaluate 在解析后的 JSON 上计算单个断言
+func (a Assertion) Evaluate(document interface{}) error {
+	actual, pathErr := EvaluateJSONPath(document, a.Path)
+
+	if a.Op == "exists" {
+		want := true
+		if a.Value != nil {
+			b, ok := a.Value.(bool)
+			if !ok {
+				return a.fail("exists 的 value 必须为 true 或 false")
+			}
+			want = b
+		}
+		if (pathErr == nil) != want {
+			if want {
+				return a.fail(fmt.Sprintf("期望存在, 实际不存在 (%v)", pathErr))
+			}
+			return a.fail(fmt.Sprintf("期望不存在, 实际 %s", formatJSONValue(actual)))
+		}
+		return nil
+	}
+	if pathErr != nil {
+		return a.fail(pathErr.Error())
+	}
+
+	switch a.Op {
+	case "eq":
+		if !valuesEqual(a.Value, actual, a.Loose) {
+			return a.mismatch(actual)
+		}
+	case "ne":
+		if valuesEqual(a.Value, actual, a.Loose) {
+			return a.mismatch(actual)
+		}
+	case "contains":
+		if !containsValue(actual, a.Value, a.Loose) {
+			return a.mismatch(actual)
+		}
+	case "regex":
+		pattern, ok := a.Value.(string)
+		if !ok {
+			return a.fail("regex 的 value 必须为字符串")
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return a.fail(fmt.Sprintf("正则表达式无效: %v", err))
+		}
+		if !re.MatchString(stringifyValue(actual)) {
+			return a.mismatch(actual)
+		}
+	case "gt", "lt":
+		want, ok1 := toFloat64(a.Value)
+		got, ok2 := toFloat64(actual)
+		if !ok1 || !ok2 {
+			return a.fail(fmt.Sprintf("%s 只能比较数字: 期望 %s, 实际 %s", a.Op, formatJSONValue(a.Value), formatJSONValue(actual)))
+		}
+		if (a.Op == "gt" && !(got > want)) || (a.Op == "lt" && !(got < want)) {
+			return a.mismatch(actual)
+		}
+	case "len":
+		length, ok := lengthOf(actual)
+		if !ok {
+			return a.fail(fmt.Sprintf("len 只适用于数组、对象或字符串, 实际 %s", formatJSONValue(actual)))
+		}
+		if !valuesEqual(a.Value, float64(length), false) {
+			return a.fail(fmt.Sprintf("期望 %s, 实际长度 %d", formatJSONValue(a.Value), length))
+		}
+	default:
+		return a.fail(fmt.Sprintf("未知的断言操作符: %s", a.Op))
+	}
+	return nil
+}
+
+// fail 生成带路径和操作符的断言失败信息
+func (a Assertion) fail(reason string) error {
+	return fmt.Errorf("断言 %s %s 失败: %s", a.Path, a.Op, reason)
+}
+
+// mismatch 生成期望值与实际值不符的断言失败信息
+func (a Assertion) mismatch(actual interface{}) error {
+	return a.fail(fmt.Sprintf("期望 %s, 实际 %s", formatJSONValue(a.Value), formatJSONValue(actual)))
+}
+
+// valuesEqual 比较期望值与实际值，JSON 类型不同即不相等：数字按数值比较，数组和对象深度比较
+// loose 为 true 时标量之间按文本比较
+func valuesEqual(expected, actual interface{}, loose bool) bool {
+	switch expected.(type) {
+	case []interface{}, map[string]interface{}:
+		return reflect.DeepEqual(normalizeJSON(expected), normalizeJSON(actual))
+	}
+	switch actual.(type) {
+	case []interface{}, map[string]interface{}:
+		return false
+	}
+	if loose {
+		return compareValues(expected, actual)
+	}
+
+	expFloat, isNum1 := toFloat64(expected)
+	actFloat, isNum2 := toFloat64(actual)
+	if isNum1 || isNum2 {
+		return isNum1 && isNum2 && expFloat == actFloat
+	}
+	return reflect.DeepEqual(expected, actual)
+}
+
+// normalizeJSON 将任意值转换为 encoding/json 解码后的标准形式，便于深度比较
+func normalizeJSON(value interface{}) interface{} {
+	bytes, err := json.Marshal(value)
+	if err != nil {
+		return value
+	}
+	var normalized interface{}
+	if err := json.Unmarshal(bytes, &normalized); err != nil {
+		return value
+	}
+	return normalized
+}
+
+// containsValue 字符串为子串匹配，数组为包含元素，对象为包含字段
+func containsValue(actual, expected interface{}, loose bool) bool {
+	switch val := actual.(type) {
+	case string:
+		return strings.Contains(val, stringifyValue(expected))
+	case []interface{}:
+		for _, item := range val {
+			if valuesEqual(expected, item, loose) {
+				return true
+			}
+		}
+	case map[string]interface{}:
+		if key, ok := expected.(string); ok {
+			_, exists := val[key]
+			return exists
+		}
+	}
+	return false
+}
+
+func lengthOf(value interface{}) (int, bool) {
+	switch val := value.(type) {
+	case []interface{}:
+		return len(val), true
+	case map[string]interface{}:
+		return len(val), true
+	case string:
+		return len([]rune(val)), true
+	default:
+		return 0, false
+	}
+}
+
+// formatJSONValue 以 JSON 形式输出值，字符串带引号，便于区分 "1" 与 1
+func formatJSONValue(value interface{}) string {
+	bytes, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(bytes)
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,267 @@
+package apisTemplate
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 认证类型（auth_profiles 中的 type）
+const (
+	AuthTypeBasic  = "basic"   // HTTP Basic 认证
+	AuthTypeBearer = "bearer"  // Bearer Token，token 通常引用运行期变量，如 "{token}"
+	AuthTypeAPIKey = "api_key" // API Key，放在请求头或查询参数中
+	AuthTypeOAuth2 = "oauth2"  // OAuth2 client_credentials / password 授权，自动获取并缓存令牌
+)
+
+// tokenExpiryMargin 令牌提前失效的时间，避免请求途中过期
+const tokenExpiryMargin = 30 * time.Second
+
+// AuthProfile 命名的认证配置，模板中通过 "auth": "admin" 引用
+// 所有字段都支持 {name} 占位符
+type AuthProfile struct {
+	Type string `json:"type" yaml:"type"` // "basic", "bearer", "api_key", "oauth2"
+	// basic 与 oauth2 password 授权
+	Username string `json:"username,omitempty" yaml:"username"`
+	Password string `json:"password,omitempty" yaml:"password"`
+	// bearer
+	Token string `json:"token,omitempty" yaml:"token"`
+	// api_key
+	In    string `json:"in,omitempty" yaml:"in"`       // "header"（默认）或 "query"
+	Name  string `json:"name,omitempty" yaml:"name"`   // 请求头名称（默认 X-API-Key）或查询参数名（默认 api_key）
+	Value string `json:"value,omitempty" yaml:"value"` // API Key 的值
+	// oauth2
+	Grant        string `json:"grant,omitempty" yaml:"grant"` // "client_credentials"（默认）或 "password"
+	TokenURL     string `json:"token_url,omitempty" yaml:"token_url"`
+	ClientID     string `json:"client_id,omitempty" yaml:"client_id"`
+	ClientSecret string `json:"client_secret,omitempty" yaml:"client_secret"`
+	Scope        string `json:"scope,omitempty" yaml:"scope"`
+}
+
+// AuthProfiles 认证配置集合: 名称 -> 配置
+type AuthProfiles map[string]AuthProfile
+
+// cachedToken 已获取的 OAuth2 令牌
+type cachedToken struct {
+	signature string // 获取令牌时使用的凭据，凭据变化后重新获取
+	value     string
+	expiresAt time.Time // 零值表示不过期
+}
+
+// Authenticator 为请求添加认证信息，并缓存 OAuth2 令牌
+// 可在多个 worker 之间共享
+type Authenticator struct {
+	profiles AuthProfiles
+	mu       sync.Mutex
+	tokens   map[string]*cachedToken
+}
+
+// NewAuthenticator 创建认证器
+func NewAuthenticator(profiles AuthProfiles) *Authenticator {
+	return &Authenticator{profiles: profiles, tokens: make(map[string]*cachedToken)}
+}
+
+// Apply 返回添加了认证信息的请求副本，不修改原始请求
+// 模板 headers 中显式设置的同名请求头优先；OAuth2 令牌在首次使用时通过 client 获取
+func (a *Authenticator) Apply(client *Client, req APIRequest, name string, params []Param) (APIRequest, error) {
+	profile, exists := a.profiles[name]
+	if !exists {
+		return req, fmt.Errorf("未定义的认证配置: %s", name)
+	}
+	profile, err := resolveProfile(profile, params)
+	if err != nil {
+		return req, fmt.Errorf("认证配置 '%s' %v", name, err)
+	}
+
+	headers := make(map[string]string, len(req.Headers)+1)
+	for k, v := range req.Headers {
+		headers[k] = v
+	}
+	req.Headers = headers
+	setHeader := func(key, value string) {
+		for k := range headers {
+			if strings.EqualFold(k, key) {
+				return
+			}
+		}
+		headers[key] = value
+	}
+
+	switch strings.ToLower(profile.Type) {
+	case AuthTypeBasic:
+		credentials := base64.StdEncoding.EncodeToString([]byte(profile.Username + ":" + profile.Password))
+		setHeader("Authorization", "Basic "+credentials)
+	case AuthTypeBearer:
+		if profile.Token == "" {
+			return req, fmt.Errorf("认证配置 '%s' 缺少 token", name)
+		}
+		setHeader("Authorization", "Bearer "+profile.Token)
+	case AuthTypeAPIKey:
+		if strings.ToLower(profile.In) == "query" {
+			key := profile.Name
+			if key == "" {
+				key = "api_key"
+			}
+			query := make(map[string]interface{}, len(req.Query)+1)
+			for k, v := range req.Query {
+				query[k] = v
+			}
+			if _, exists := query[key]; !exists {
+				query[key] = profile.Value
+			}
+			req.Query = query
+		} else {
+			key := profile.Name
+			if key == "" {
+				key = "X-API-Key"
+			}
+			setHeader(key, profile.Value)
+		}
+	case AuthTypeOAuth2:
+		token, err := a.oauth2Token(client, name, profile)
+		if err != nil {
+			return req, fmt.Errorf("认证配置 '%s' 获取令牌失败: %v", name, err)
+		}
+		setHeader("Authorization", "Bearer "+token)
+	default:
+		return req, fmt.Errorf("认证配置 '%s' 的类型无效: %s", name, profile.Type)
+	}
+	return req, nil
+}
+
+// CredentialKeys 返回认证配置写入凭据的请求头名称与查询参数名，用于在日志和报告中隐藏凭据
+// 认证配置不存在时返回空
+func (a *Authenticator) CredentialKeys(name string) (header, query string) {
+	profile, exists := a.profiles[name]
+	if !exists {
+		return "", ""
+	}
+	switch strings.ToLower(profile.Type) {
+	case AuthTypeBasic, AuthTypeBearer, AuthTypeOAuth2:
+		return "Authorization", ""
+	case AuthTypeAPIKey:
+		if strings.ToLower(profile.In) == "query" {
+			if profile.Name == "" {
+				return "", "api_key"
+			}
+			return "", profile.Name
+		}
+		if profile.Name == "" {
+			return "X-API-Key", ""
+		}
+		return profile.Name, ""
+	}
+	return "", ""
+}
+
+// Invalidate 丢弃缓存的令牌，返回是否可以重新获取（仅 OAuth2 配置可刷新）
+// 收到 401 后调用，下次 Apply 时重新获取令牌
+func (a *Authenticator) Invalidate(name string) bool {
+	profile, exists := a.profiles[name]
+	if !exists || strings.ToLower(profile.Type) != AuthTypeOAuth2 {
+		return false
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	delete(a.tokens, name)
+	return true
+}
+
+// oauth2Token 返回缓存中未过期的令牌，没有时向 token_url 申请
+// 持有锁期间申请令牌，并发请求只会触发一次获取
+func (a *Authenticator) oauth2Token(client *Client, name string, profile AuthProfile) (string, error) {
+	signature := strings.Join([]string{profile.Grant, profile.TokenURL, profile.ClientID, profile.ClientSecret,
+		profile.Scope, profile.Username, profile.Password}, "\x00")
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if cached, ok := a.tokens[name]; ok && cached.signature == signature &&
+		(cached.expiresAt.IsZero() || time.Now().Before(cached.expiresAt)) {
+		return cached.value, nil
+	}
+
+	token, err := fetchOAuth2Token(client, profile)
+	if err != nil {
+		return "", err
+	}
+	token.signature = signature
+	a.tokens[name] = token
+	return token.value, nil
+}
+
+// fetchOAuth2Token 按 RFC 6749 以表单方式请求令牌
+func fetchOAuth2Token(client *Client, profile AuthProfile) (*cachedToken, error) {
+	if profile.TokenURL == "" {
+		return nil, fmt.Errorf("缺少 token_url")
+	}
+	grant := strings.ToLower(profile.Grant)
+	if grant == "" {
+		grant = "client_credentials"
+	}
+
+	data := map[string]interface{}{"grant_type": grant}
+	switch grant {
+	case "client_credentials":
+	case "password":
+		data["username"] = profile.Username
+		data["password"] = profile.Password
+	default:
+		return nil, fmt.Errorf("不支持的 grant: %s", profile.Grant)
+	}
+	if profile.ClientID != "" {
+		data["client_id"] = profile.ClientID
+	}
+	if profile.ClientSecret != "" {
+		data["client_secret"] = profile.ClientSecret
+	}
+	if profile.Scope != "" {
+		data["scope"] = profile.Scope
+	}
+
+	resp, err := client.Execute(APIRequest{
+		URL:      profile.TokenURL,
+		Method:   http.MethodPost,
+		BodyType: BodyTypeForm,
+		Data:     data,
+		Headers:  map[string]string{"Accept": "application/json"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("令牌接口返回 %d: %s", resp.StatusCode, truncate(resp.RawBody, 200))
+	}
+	accessToken, _ := resp.Body["access_token"].(string)
+	if accessToken == "" {
+		return nil, fmt.Errorf("令牌接口响应中缺少 access_token: %s", truncate(resp.RawBody, 200))
+	}
+
+	token := &cachedToken{value: accessToken}
+	if expiresIn, ok := toFloat64(resp.Body["expires_in"]); ok && expiresIn > 0 {
+		lifetime := time.Duration(expiresIn) * time.Second
+		margin := tokenExpiryMargin
+		if margin > lifetime/2 {
+			margin = lifetime / 2
+		}
+		token.expiresAt = time.Now().Add(lifetime - margin)
+	}
+	return token, nil
+}
+
+// resolveProfile 替换认证配置中的占位符，存在未解析的占位符时返回错误
+func resolveProfile(profile AuthProfile, params []Param) (AuthProfile, error) {
+	fields := []*string{&profile.Username, &profile.Password, &profile.Token, &profile.Name, &profile.Value,
+		&profile.TokenURL, &profile.ClientID, &profile.ClientSecret, &profile.Scope}
+	var missing []string
+	for _, field := range fields {
+		*field = ReplaceString(*field, params)
+		missing = append(missing, placeholderPattern.FindAllString(*field, -1)...)
+	}
+	if len(missing) > 0 {
+		return profile, fmt.Errorf("存在未解析的占位符: %s", strings.Join(missing, ", "))
+	}
+	return profile, nil
+}
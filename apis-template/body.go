@@ -0,0 +1,174 @@
+package apisTemplate
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// 请求体类型（模板中的 body_type），默认为 json
+const (
+	BodyTypeJSON      = "json"      // data 序列化为 JSON
+	BodyTypeForm      = "form"      // data 编码为 application/x-www-form-urlencoded
+	BodyTypeMultipart = "multipart" // data 为文本字段，files 为上传文件
+	BodyTypeRaw       = "raw"       // body 原样发送（text/plain）
+	BodyTypeXML       = "xml"       // body 原样发送（application/xml）
+)
+
+// buildBody 根据 body_type 生成请求体及其默认的 Content-Type
+// 没有请求体时返回 nil reader 和空 Content-Type
+func buildBody(req APIRequest) (io.Reader, string, error) {
+	bodyType := strings.ToLower(req.BodyType)
+	if len(req.Files) > 0 && bodyType != BodyTypeMultipart {
+		return nil, "", fmt.Errorf("files 只能用于 body_type: multipart")
+	}
+
+	switch bodyType {
+	case "", BodyTypeJSON:
+		if len(req.Data) == 0 {
+			return nil, "", nil
+		}
+		jsonBytes, err := json.Marshal(req.Data)
+		if err != nil {
+			return nil, "", fmt.Errorf("请求体序列化失败: %v", err)
+		}
+		return bytes.NewReader(jsonBytes), "application/json", nil
+
+	case BodyTypeForm:
+		if len(req.Data) == 0 {
+			return nil, "", nil
+		}
+		return strings.NewReader(formValues(req.Data).Encode()), "application/x-www-form-urlencoded", nil
+
+	case BodyTypeMultipart:
+		return buildMultipart(req)
+
+	case BodyTypeRaw:
+		return strings.NewReader(req.Body), "text/plain; charset=utf-8", nil
+
+	case BodyTypeXML:
+		return strings.NewReader(req.Body), "application/xml; charset=utf-8", nil
+
+	default:
+		return nil, "", fmt.Errorf("不支持的 body_type: %s", req.BodyType)
+	}
+}
+
+// formValues 将 data 转换为表单字段，数组展开为同名的多个字段
+func formValues(data map[string]interface{}) url.Values {
+	values := url.Values{}
+	for key, value := range data {
+		if items, ok := value.([]interface{}); ok {
+			for _, item := range items {
+				values.Add(key, stringifyValue(item))
+			}
+			continue
+		}
+		values.Set(key, stringifyValue(value))
+	}
+	return values
+}
+
+// buildMultipart 生成 multipart/form-data 请求体，字段按名称排序以保证顺序稳定
+func buildMultipart(req APIRequest) (io.Reader, string, error) {
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+
+	values := formValues(req.Data)
+	for _, key := range sortedMapKeys(values) {
+		for _, value := range values[key] {
+			if err := writer.WriteField(key, value); err != nil {
+				return nil, "", fmt.Errorf("写入表单字段 '%s' 失败: %v", key, err)
+			}
+		}
+	}
+
+	for _, field := range sortedFileFields(req.Files) {
+		path := req.Files[field]
+		if err := writeFilePart(writer, field, path); err != nil {
+			return nil, "", err
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, "", fmt.Errorf("生成 multipart 请求体失败: %v", err)
+	}
+	return &buf, writer.FormDataContentType(), nil
+}
+
+func writeFilePart(writer *multipart.Writer, field, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("打开上传文件 '%s' 失败: %v", path, err)
+	}
+	defer file.Close()
+
+	part, err := writer.CreateFormFile(field, filepath.Base(path))
+	if err != nil {
+		return fmt.Errorf("创建文件字段 '%s' 失败: %v", field, err)
+	}
+	if _, err := io.Copy(part, file); err != nil {
+		return fmt.Errorf("读取上传文件 '%s' 失败: %v", path, err)
+	}
+	return nil
+}
+
+func sortedMapKeys(values url.Values) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func sortedFileFields(files map[string]string) []string {
+	fields := make([]string, 0, len(files))
+	for field := range files {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// DescribeBody 返回请求体的可读描述，用于报告展示
+// multipart 的文件部分以 field=@path 表示，不读取文件内容
+func DescribeBody(req APIRequest) string {
+	switch strings.ToLower(req.BodyType) {
+	case BodyTypeForm:
+		if len(req.Data) == 0 {
+			return ""
+		}
+		return formValues(req.Data).Encode()
+	case BodyTypeMultipart:
+		var lines []string
+		values := formValues(req.Data)
+		for _, key := range sortedMapKeys(values) {
+			for _, value := range values[key] {
+				lines = append(lines, fmt.Sprintf("%s=%s", key, value))
+			}
+		}
+		for _, field := range sortedFileFields(req.Files) {
+			lines = append(lines, fmt.Sprintf("%s=@%s", field, req.Files[field]))
+		}
+		return strings.Join(lines, "\n")
+	case BodyTypeRaw, BodyTypeXML:
+		return req.Body
+	default:
+		if len(req.Data) == 0 {
+			return ""
+		}
+		body, err := json.MarshalIndent(req.Data, "", "  ")
+		if err != nil {
+			return ""
+		}
+		return string(body)
+	}
+}
@@ -0,0 +1,134 @@
+package apisTemplate
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"os"
+	"time"
+)
+
+// 客户端默认值
+const (
+	DefaultClientTimeout = 10000 // 请求超时（毫秒）
+	DefaultMaxRedirects  = 10    // 最多跟随的重定向次数
+)
+
+// ClientConfig API 客户端配置（配置文件中的 api_client）
+type ClientConfig struct {
+	Timeout            int    `json:"timeout" yaml:"timeout"`                           // 请求超时（毫秒），默认 10000
+	InsecureSkipVerify *bool  `json:"insecure_skip_verify" yaml:"insecure_skip_verify"` // 跳过证书校验，未设置时使用 ignore_https_errors
+	CAFile             string `json:"ca_file" yaml:"ca_file"`                           // 额外信任的 CA 证书（PEM），用于设备自签名证书
+	ClientCert         string `json:"client_cert" yaml:"client_cert"`                   // 客户端证书（PEM），需与 client_key 同时配置
+	ClientKey          string `json:"client_key" yaml:"client_key"`                     // 客户端私钥（PEM）
+	Proxy              string `json:"proxy" yaml:"proxy"`                               // 代理地址，如 http://127.0.0.1:8888；为空时使用 HTTP_PROXY 等环境变量
+	FollowRedirects    *bool  `json:"follow_redirects" yaml:"follow_redirects"`         // 是否跟随重定向，默认 true；为 false 时直接返回 3xx 响应
+	MaxRedirects       int    `json:"max_redirects" yaml:"max_redirects"`               // 最多跟随的重定向次数，默认 10
+}
+
+// Client 可配置的 API 客户端
+// 同一个 Client 的请求共享 Cookie；NewSession 创建共享连接池但 Cookie 独立的新会话
+type Client struct {
+	transport http.RoundTripper
+	timeout   time.Duration
+	redirect  func(req *http.Request, via []*http.Request) error
+	http      *http.Client
+}
+
+// defaultClient 供 ExecuteRequest 使用，不保存 Cookie
+var defaultClient = &Client{http: &http.Client{Timeout: DefaultClientTimeout * time.Millisecond}}
+
+// NewClient 根据配置创建 API 客户端
+func NewClient(config ClientConfig) (*Client, error) {
+	tlsConfig := &tls.Config{}
+	if config.InsecureSkipVerify != nil {
+		tlsConfig.InsecureSkipVerify = *config.InsecureSkipVerify
+	}
+	if config.CAFile != "" {
+		pem, err := os.ReadFile(config.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("读取 CA 证书失败: %v", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("CA 证书 '%s' 中没有有效的 PEM 证书", config.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if config.ClientCert != "" || config.ClientKey != "" {
+		if config.ClientCert == "" || config.ClientKey == "" {
+			return nil, fmt.Errorf("client_cert 与 client_key 必须同时配置")
+		}
+		cert, err := tls.LoadX509KeyPair(config.ClientCert, config.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("加载客户端证书失败: %v", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	if config.Proxy != "" {
+		proxyURL, err := url.Parse(config.Proxy)
+		if err != nil || proxyURL.Host == "" {
+			return nil, fmt.Errorf("无效的代理地址: %s", config.Proxy)
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	timeout := config.Timeout
+	if timeout <= 0 {
+		timeout = DefaultClientTimeout
+	}
+	maxRedirects := config.MaxRedirects
+	if maxRedirects <= 0 {
+		maxRedirects = DefaultMaxRedirects
+	}
+	followRedirects := config.FollowRedirects == nil || *config.FollowRedirects
+
+	client := &Client{
+		transport: transport,
+		timeout:   time.Duration(timeout) * time.Millisecond,
+		redirect: func(req *http.Request, via []*http.Request) error {
+			if !followRedirects {
+				return http.ErrUseLastResponse
+			}
+			if len(via) >= maxRedirects {
+				return fmt.Errorf("重定向次数超过 %d 次", maxRedirects)
+			}
+			return nil
+		},
+	}
+	return client.NewSession(), nil
+}
+
+// NewSession 创建新的会话：沿用连接池、TLS 与代理配置，使用新的 Cookie Jar
+func (c *Client) NewSession() *Client {
+	jar, _ := cookiejar.New(nil)
+	session := *c
+	session.http = &http.Client{
+		Transport:     c.transport,
+		Timeout:       c.timeout,
+		CheckRedirect: c.redirect,
+		Jar:           jar,
+	}
+	return &session
+}
+
+// Execute 使用该客户端发送请求
+func (c *Client) Execute(req APIRequest) (*APIResponse, error) {
+	return execute(c.http, req)
+}
+
+// isTimeout 判断错误是否为请求超时
+func isTimeout(err error) bool {
+	var netErr interface{ Timeout() bool }
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
@@ -0,0 +1,390 @@
+package apisTemplate
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// curlFlagsWithValue 带参数值但导入时忽略的 curl 选项
+var curlFlagsWithValue = map[string]bool{
+	"-o": true, "--output": true, "-m": true, "--max-time": true, "--connect-timeout": true,
+	"-x": true, "--proxy": true, "--retry": true, "-w": true, "--write-out": true,
+	"--cacert": true, "-E": true, "--cert": true, "--key": true, "-c": true, "--cookie-jar": true,
+	"--resolve": true, "--limit-rate": true, "-r": true, "--range": true, "-T": true, "--upload-file": true,
+}
+
+// curlCommand 解析后的 curl 命令
+type curlCommand struct {
+	method    string
+	url       string
+	headers   map[string]string
+	data      []string // -d / --data-raw 等，按出现顺序以 & 连接
+	encoded   []string // --data-urlencode，发送前已编码
+	form      []string // -F name=value / name=@file
+	json      bool     // --json
+	get       bool     // -G：数据作为查询参数
+	basicAuth string   // -u user:password
+}
+
+// ImportCurl 将一条 curl 命令转换为 API 模板，name 为模板名（为空时由方法与路径生成）
+// 支持 -X、-H、-d / --data-raw / --data-binary / --data-urlencode、--json、-F、-u、-b、-A、-e、-G 与 --url，
+// 其他选项（如 -k、-L、-s）会被忽略
+func ImportCurl(command, name string) (*ImportResult, error) {
+	args, err := splitShellWords(command)
+	if err != nil {
+		return nil, err
+	}
+	if len(args) == 0 || args[0] != "curl" {
+		return nil, fmt.Errorf("不是 curl 命令")
+	}
+
+	cmd, warnings, err := parseCurlArgs(args[1:])
+	if err != nil {
+		return nil, err
+	}
+	req, err := cmd.toRequest()
+	if err != nil {
+		return nil, err
+	}
+
+	if name == "" {
+		path := req.URL
+		if parsed, err := url.Parse(req.URL); err == nil && parsed.Host != "" {
+			path = parsed.Path
+		}
+		name = req.Method + "_" + templateKeyPart(path)
+	}
+	return &ImportResult{
+		Templates: APITemplates{name: req},
+		Warnings:  warnings,
+	}, nil
+}
+
+func parseCurlArgs(args []string) (*curlCommand, []string, error) {
+	cmd := &curlCommand{headers: make(map[string]string)}
+	var warnings []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if !strings.HasPrefix(arg, "-") || arg == "-" {
+			if cmd.url != "" {
+				return nil, nil, fmt.Errorf("只支持一个 URL: %s", arg)
+			}
+			cmd.url = arg
+			continue
+		}
+
+		// --name=value 与 -XPOST 形式
+		flagName, value, hasValue := arg, "", false
+		if strings.HasPrefix(arg, "--") {
+			flagName, value, hasValue = strings.Cut(arg, "=")
+		} else if len(arg) > 2 && strings.Contains("XHdFubAeo", arg[1:2]) {
+			flagName, value, hasValue = arg[:2], arg[2:], true
+		}
+		next := func() (string, error) {
+			if hasValue {
+				return value, nil
+			}
+			if i+1 >= len(args) {
+				return "", fmt.Errorf("选项 %s 缺少参数", flagName)
+			}
+			i++
+			return args[i], nil
+		}
+
+		var err error
+		switch flagName {
+		case "-X", "--request":
+			var method string
+			method, err = next()
+			cmd.method = strings.ToLower(method)
+		case "-H", "--header":
+			var header string
+			if header, err = next(); err == nil {
+				key, val, _ := strings.Cut(header, ":")
+				cmd.headers[strings.TrimSpace(key)] = strings.TrimSpace(val)
+			}
+		case "-d", "--data", "--data-raw", "--data-binary", "--data-ascii":
+			var data string
+			if data, err = next(); err == nil {
+				if strings.HasPrefix(data, "@") && flagName != "--data-raw" {
+					warnings = append(warnings, fmt.Sprintf("不支持从文件读取请求体: %s", data))
+				}
+				cmd.data = append(cmd.data, data)
+			}
+		case "--data-urlencode":
+			var data string
+			if data, err = next(); err == nil {
+				key, val, found := strings.Cut(data, "=")
+				if found {
+					cmd.encoded = append(cmd.encoded, url.QueryEscape(key)+"="+url.QueryEscape(val))
+				} else {
+					cmd.encoded = append(cmd.encoded, url.QueryEscape(data))
+				}
+			}
+		case "--json":
+			var data string
+			if data, err = next(); err == nil {
+				cmd.json = true
+				cmd.data = append(cmd.data, data)
+			}
+		case "-F", "--form", "--form-string":
+			var field string
+			if field, err = next(); err == nil {
+				cmd.form = append(cmd.form, field)
+			}
+		case "-u", "--user":
+			cmd.basicAuth, err = next()
+		case "-b", "--cookie":
+			var cookie string
+			if cookie, err = next(); err == nil {
+				cmd.headers["Cookie"] = cookie
+			}
+		case "-A", "--user-agent":
+			var agent string
+			if agent, err = next(); err == nil {
+				cmd.headers["User-Agent"] = agent
+			}
+		case "-e", "--referer":
+			var referer string
+			if referer, err = next(); err == nil {
+				cmd.headers["Referer"] = referer
+			}
+		case "--url":
+			cmd.url, err = next()
+		case "-G", "--get":
+			cmd.get = true
+		case "-I", "--head":
+			cmd.method = "head"
+		default:
+			if curlFlagsWithValue[flagName] {
+				_, err = next()
+			}
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	if cmd.url == "" {
+		return nil, nil, fmt.Errorf("curl 命令缺少 URL")
+	}
+	return cmd, warnings, nil
+}
+
+// toRequest 转换为 API 模板：查询字符串拆分到 query；JSON 对象请求体转换为 data，表单转换为 form / multipart
+func (c *curlCommand) toRequest() (APIRequest, error) {
+	rawURL := c.url
+	if !strings.Contains(rawURL, "://") {
+		rawURL = "http://" + rawURL
+	}
+	base, rawQuery, _ := strings.Cut(rawURL, "?")
+	req := APIRequest{URL: base, Method: c.method}
+
+	body := strings.Join(append(append([]string{}, c.data...), c.encoded...), "&")
+	if c.get && body != "" {
+		rawQuery = strings.Trim(rawQuery+"&"+body, "&")
+		body = ""
+	}
+	if rawQuery != "" {
+		values, err := url.ParseQuery(rawQuery)
+		if err != nil {
+			return req, fmt.Errorf("无法解析查询字符串: %v", err)
+		}
+		req.Query = formValuesToData(values)
+	}
+
+	contentType := ""
+	for key, value := range c.headers {
+		if req.Headers == nil {
+			req.Headers = make(map[string]string)
+		}
+		req.Headers[key] = value
+		if strings.EqualFold(key, "Content-Type") {
+			contentType = strings.ToLower(value)
+		}
+	}
+	if c.basicAuth != "" && !hasHeader(req.Headers, "Authorization") {
+		if req.Headers == nil {
+			req.Headers = make(map[string]string)
+		}
+		req.Headers["Authorization"] = "Basic " + base64.StdEncoding.EncodeToString([]byte(c.basicAuth))
+	}
+
+	switch {
+	case len(c.form) > 0:
+		req.BodyType = BodyTypeMultipart
+		req.Data = make(map[string]interface{})
+		for _, field := range c.form {
+			key, value, _ := strings.Cut(field, "=")
+			if strings.HasPrefix(value, "@") {
+				if req.Files == nil {
+					req.Files = make(map[string]string)
+				}
+				// 去掉 ;type=... 等附加属性
+				path, _, _ := strings.Cut(value[1:], ";")
+				req.Files[key] = path
+				continue
+			}
+			addFormValue(req.Data, key, value)
+		}
+		// multipart 的 Content-Type 需要由程序生成 boundary
+		deleteHeader(req.Headers, "Content-Type")
+	case body == "":
+	case c.json || strings.Contains(contentType, "json"):
+		data, ok := parseJSONObjectBody(body)
+		if !ok {
+			req.BodyType = BodyTypeRaw
+			req.Body = body
+			break
+		}
+		req.Data = data
+	case contentType == "" || strings.Contains(contentType, "x-www-form-urlencoded"):
+		if data, ok := parseJSONObjectBody(body); ok && contentType == "" {
+			req.Data = data
+			break
+		}
+		values, err := url.ParseQuery(body)
+		if err != nil {
+			req.BodyType = BodyTypeRaw
+			req.Body = body
+			break
+		}
+		req.BodyType = BodyTypeForm
+		req.Data = formValuesToData(values)
+		deleteHeader(req.Headers, "Content-Type")
+	case strings.Contains(contentType, "xml"):
+		req.BodyType = BodyTypeXML
+		req.Body = body
+	default:
+		req.BodyType = BodyTypeRaw
+		req.Body = body
+	}
+	if len(req.Headers) == 0 {
+		req.Headers = nil
+	}
+
+	if req.Method == "" {
+		req.Method = "get"
+		if body != "" || len(c.form) > 0 {
+			req.Method = "post"
+		}
+	}
+	return req, nil
+}
+
+// splitShellWords 按 shell 规则拆分命令行：支持单引号、双引号、$'...'、反斜杠转义与续行
+func splitShellWords(command string) ([]string, error) {
+	// Windows 命令行中的 ^ 续行与 bash 的 \ 续行
+	command = regexp.MustCompile(`[\\^]\r?\n`).ReplaceAllString(command, " ")
+
+	var (
+		words   []string
+		current strings.Builder
+		inWord  bool
+	)
+	runes := []rune(command)
+	for i := 0; i < len(runes); i++ {
+		ch := runes[i]
+		switch {
+		case ch == ' ' || ch == '\t' || ch == '\n' || ch == '\r':
+			if inWord {
+				words = append(words, current.String())
+				current.Reset()
+				inWord = false
+			}
+		case ch == '\'':
+			inWord = true
+			end := indexRune(runes, i+1, '\'')
+			if end < 0 {
+				return nil, fmt.Errorf("单引号未闭合")
+			}
+			current.WriteString(string(runes[i+1 : end]))
+			i = end
+		case ch == '$' && i+1 < len(runes) && runes[i+1] == '\'':
+			inWord = true
+			i += 2
+			for ; i < len(runes) && runes[i] != '\''; i++ {
+				if runes[i] == '\\' && i+1 < len(runes) {
+					i++
+					switch runes[i] {
+					case 'n':
+						current.WriteRune('\n')
+					case 't':
+						current.WriteRune('\t')
+					case 'r':
+						current.WriteRune('\r')
+					default:
+						current.WriteRune(runes[i])
+					}
+					continue
+				}
+				current.WriteRune(runes[i])
+			}
+			if i >= len(runes) {
+				return nil, fmt.Errorf("单引号未闭合")
+			}
+		case ch == '"':
+			inWord = true
+			i++
+			for ; i < len(runes) && runes[i] != '"'; i++ {
+				if runes[i] == '\\' && i+1 < len(runes) && strings.ContainsRune("\"\\$`", runes[i+1]) {
+					i++
+				}
+				current.WriteRune(runes[i])
+			}
+			if i >= len(runes) {
+				return nil, fmt.Errorf("双引号未闭合")
+			}
+		case ch == '\\' && i+1 < len(runes):
+			inWord = true
+			i++
+			current.WriteRune(runes[i])
+		default:
+			inWord = true
+			current.WriteRune(ch)
+		}
+	}
+	if inWord {
+		words = append(words, current.String())
+	}
+	return words, nil
+}
+
+func indexRune(runes []rune, from int, target rune) int {
+	for i := from; i < len(runes); i++ {
+		if runes[i] == target {
+			return i
+		}
+	}
+	return -1
+}
+
+// formValuesToData 将表单值转换为 data，同名字段转换为数组
+func formValuesToData(values url.Values) map[string]interface{} {
+	data := make(map[string]interface{}, len(values))
+	for key, items := range values {
+		for _, item := range items {
+			addFormValue(data, key, item)
+		}
+	}
+	return data
+}
+
+func hasHeader(headers map[string]string, name string) bool {
+	for key := range headers {
+		if strings.EqualFold(key, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func deleteHeader(headers map[string]string, name string) {
+	for key := range headers {
+		if strings.EqualFold(key, name) {
+			delete(headers, key)
+		}
+	}
+}
@@ -1,8 +1,8 @@
 package apisTemplate
 
 import (
-	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -13,40 +13,49 @@ import (
 // APIResponse 封装执行后的结果
 type APIResponse struct {
 	StatusCode int                    // HTTP 状态码
-	Body       map[string]interface{} // 解析后的 JSON 响应体
+	Body       map[string]interface{} // 解析后的 JSON 响应体（仅当根节点为对象时有内容）
+	JSON       interface{}            // 解析后的完整 JSON（根节点可以是对象、数组或标量），非 JSON 时为 nil
 	RawBody    string                 // 原始响应文本 (用于调试)
 	Header     http.Header            // 响应头
+	Duration   time.Duration          // 请求耗时（从发送请求到读取完响应体）
+	parsed     bool                   // 响应体是否成功解析为 JSON
 }
 
-// ExecuteRequest 发送 HTTP 请求
+// ExecuteRequest 使用默认客户端（超时 10 秒，不保存 Cookie）发送 HTTP 请求
+// 需要 Cookie 会话、证书或代理时使用 NewClient 创建的 Client
 func ExecuteRequest(req APIRequest) (*APIResponse, error) {
-	// 1. 准备请求体 (Body)
-	var bodyReader io.Reader
-	if len(req.Data) > 0 {
-		jsonBytes, err := json.Marshal(req.Data)
-		if err != nil {
-			return nil, fmt.Errorf("请求体序列化失败: %v", err)
-		}
-		bodyReader = bytes.NewBuffer(jsonBytes)
+	return defaultClient.Execute(req)
+}
+
+// execute 发送 HTTP 请求
+func execute(client *http.Client, req APIRequest) (*APIResponse, error) {
+	// 1. 准备请求体 (Body)，按 body_type 编码
+	bodyReader, contentType, err := buildBody(req)
+	if err != nil {
+		return nil, err
 	}
 
 	// 2. 创建 HTTP Request 对象
-	httpReq, err := http.NewRequest(strings.ToUpper(req.Method), req.URL, bodyReader)
+	httpReq, err := http.NewRequest(strings.ToUpper(req.Method), BuildURL(req), bodyReader)
 	if err != nil {
 		return nil, fmt.Errorf("创建请求失败: %v", err)
 	}
 
-	// 3. 设置请求头 (Headers)
+	// 3. 设置请求头 (Headers)，模板中显式设置的 Content-Type 优先
+	if contentType != "" {
+		httpReq.Header.Set("Content-Type", contentType)
+	}
 	for k, v := range req.Headers {
 		httpReq.Header.Set(k, v)
 	}
 
 	// 4. 发起网络请求
-	client := &http.Client{
-		Timeout: 10 * time.Second, // 设置超时防止卡死
-	}
+	start := time.Now()
 	resp, err := client.Do(httpReq)
 	if err != nil {
+		if isTimeout(err) {
+			return nil, fmt.Errorf("网络请求超时 (%v): %v", client.Timeout, err)
+		}
 		return nil, fmt.Errorf("网络请求发送失败: %v", err)
 	}
 	defer resp.Body.Close()
@@ -56,19 +65,42 @@ func ExecuteRequest(req APIRequest) (*APIResponse, error) {
 	if err != nil {
 		return nil, fmt.Errorf("读取响应失败: %v", err)
 	}
+	duration := time.Since(start)
 
 	// 6. 封装结果
 	result := &APIResponse{
 		StatusCode: resp.StatusCode,
 		Header:     resp.Header,
 		RawBody:    string(respBytes),
-		Body:       make(map[string]interface{}),
+		Duration:   duration,
 	}
+	result.parseBody(respBytes)
 
-	// 尝试将响应解析为 JSON Map
-	if len(respBytes) > 0 {
-		_ = json.Unmarshal(respBytes, &result.Body)
+	return result, nil
+}
+
+// parseBody 尝试将响应解析为 JSON，并记录是否解析成功
+func (r *APIResponse) parseBody(data []byte) {
+	r.Body = make(map[string]interface{})
+	r.JSON = nil
+	r.parsed = false
+	if len(data) == 0 {
+		return
+	}
+	if err := json.Unmarshal(data, &r.JSON); err != nil {
+		r.JSON = nil
+		return
+	}
+	r.parsed = true
+	if body, ok := r.JSON.(map[string]interface{}); ok {
+		r.Body = body
 	}
+}
 
-	return result, nil
+// document 返回用于断言和取值的 JSON 根节点（响应为 null 时返回 nil），响应不是 JSON 时返回错误
+func (r *APIResponse) document() (interface{}, error) {
+	if !r.parsed {
+		return nil, errors.New("响应不是 JSON")
+	}
+	return r.JSON, nil
 }
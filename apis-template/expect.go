@@ -0,0 +1,192 @@
+package apisTemplate
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// HeaderExpect 单个响应头的期望
+// JSON 中可以直接写字符串（等价于 equals），也可以写 {"equals"|"contains"|"regex": "..."}
+type HeaderExpect struct {
+	Equals   string `json:"equals,omitempty"`
+	Contains string `json:"contains,omitempty"`
+	Regex    string `json:"regex,omitempty"`
+}
+
+// UnmarshalJSON 支持字符串简写
+func (h *HeaderExpect) UnmarshalJSON(data []byte) error {
+	var equals string
+	if err := json.Unmarshal(data, &equals); err == nil {
+		*h = HeaderExpect{Equals: equals}
+		return nil
+	}
+	type plain HeaderExpect
+	return json.Unmarshal(data, (*plain)(h))
+}
+
+// UnmarshalJSON 解析 expect 配置
+// status 可以是状态码 200、范围 "2xx"，或由二者组成的数组 [200, 201] / ["2xx", 304]
+func (e *ExpectConfig) UnmarshalJSON(data []byte) error {
+	type plain ExpectConfig
+	aux := struct {
+		*plain
+		Status json.RawMessage `json:"status"`
+	}{plain: (*plain)(e)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	e.Status = 0
+	e.StatusIn = nil
+	if len(aux.Status) == 0 || string(aux.Status) == "null" {
+		return nil
+	}
+	var code int
+	if err := json.Unmarshal(aux.Status, &code); err == nil {
+		e.Status = code
+		return nil
+	}
+
+	var items []interface{}
+	if err := json.Unmarshal(aux.Status, &items); err != nil {
+		var single interface{}
+		if err := json.Unmarshal(aux.Status, &single); err != nil {
+			return err
+		}
+		items = []interface{}{single}
+	}
+	for _, item := range items {
+		pattern, err := parseStatusPattern(item)
+		if err != nil {
+			return err
+		}
+		e.StatusIn = append(e.StatusIn, pattern)
+	}
+	return nil
+}
+
+// MarshalJSON 与 UnmarshalJSON 对应：status 为单个状态码、单个范围或数组
+func (e ExpectConfig) MarshalJSON() ([]byte, error) {
+	type plain ExpectConfig
+	aux := struct {
+		Status interface{} `json:"status,omitempty"`
+		plain
+	}{plain: plain(e)}
+	switch {
+	case len(e.StatusIn) == 1:
+		aux.Status = e.StatusIn[0]
+	case len(e.StatusIn) > 1:
+		aux.Status = e.StatusIn
+	case e.Status != 0:
+		aux.Status = e.Status
+	}
+	return json.Marshal(aux)
+}
+
+// parseStatusPattern 将状态码或 "2xx" 形式的范围统一为 3 位字符串
+func parseStatusPattern(item interface{}) (string, error) {
+	var pattern string
+	switch v := item.(type) {
+	case float64:
+		pattern = strconv.Itoa(int(v))
+	case string:
+		pattern = strings.ToLower(strings.TrimSpace(v))
+	default:
+		return "", fmt.Errorf("无效的 status: %v", item)
+	}
+	if !regexp.MustCompile(`^[1-5][0-9x]{2}$`).MatchString(pattern) {
+		return "", fmt.Errorf("无效的 status: %v，应为状态码或 \"2xx\" 形式的范围", item)
+	}
+	return pattern, nil
+}
+
+// checkStatus 校验状态码，未配置 status 时不校验
+func (e ExpectConfig) checkStatus(actual int) error {
+	if len(e.StatusIn) == 0 {
+		if e.Status != 0 && actual != e.Status {
+			return fmt.Errorf("HTTP状态码不匹配: 期望 %d, 实际 %d", e.Status, actual)
+		}
+		return nil
+	}
+
+	code := strconv.Itoa(actual)
+	for _, pattern := range e.StatusIn {
+		matched := len(code) == len(pattern)
+		for i := 0; matched && i < len(pattern); i++ {
+			matched = pattern[i] == 'x' || pattern[i] == code[i]
+		}
+		if matched {
+			return nil
+		}
+	}
+	return fmt.Errorf("HTTP状态码不匹配: 期望 %s, 实际 %d", strings.Join(e.StatusIn, " / "), actual)
+}
+
+// checkHeaders 校验响应头，头名称不区分大小写，多个同名头以 ", " 连接后比较
+func (e ExpectConfig) checkHeaders(header http.Header) error {
+	for _, name := range sortedHeaderNames(e.Headers) {
+		want := e.Headers[name]
+		values := header.Values(name)
+		if len(values) == 0 {
+			return fmt.Errorf("响应头缺少: %s", name)
+		}
+		actual := strings.Join(values, ", ")
+
+		if want.Equals != "" && actual != want.Equals {
+			return fmt.Errorf("响应头 '%s' 校验失败: 期望 '%s', 实际 '%s'", name, want.Equals, actual)
+		}
+		if want.Contains != "" && !strings.Contains(actual, want.Contains) {
+			return fmt.Errorf("响应头 '%s' 校验失败: 期望包含 '%s', 实际 '%s'", name, want.Contains, actual)
+		}
+		if want.Regex != "" {
+			re, err := regexp.Compile(want.Regex)
+			if err != nil {
+				return fmt.Errorf("响应头 '%s' 的正则表达式无效: %v", name, err)
+			}
+			if !re.MatchString(actual) {
+				return fmt.Errorf("响应头 '%s' 校验失败: 期望匹配 '%s', 实际 '%s'", name, want.Regex, actual)
+			}
+		}
+	}
+	return nil
+}
+
+// checkRawBody 校验原始响应文本，适用于非 JSON 响应
+func (e ExpectConfig) checkRawBody(raw string) error {
+	if e.RawContains != "" && !strings.Contains(raw, e.RawContains) {
+		return fmt.Errorf("响应内容校验失败: 期望包含 '%s', 实际 '%s'", e.RawContains, truncate(raw, 200))
+	}
+	if e.RawRegex != "" {
+		re, err := regexp.Compile(e.RawRegex)
+		if err != nil {
+			return fmt.Errorf("raw_regex 正则表达式无效: %v", err)
+		}
+		if !re.MatchString(raw) {
+			return fmt.Errorf("响应内容校验失败: 期望匹配 '%s', 实际 '%s'", e.RawRegex, truncate(raw, 200))
+		}
+	}
+	return nil
+}
+
+func sortedHeaderNames(headers map[string]HeaderExpect) []string {
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// truncate 截断过长的文本，避免错误信息中输出整个响应体
+func truncate(text string, max int) string {
+	runes := []rune(text)
+	if len(runes) <= max {
+		return text
+	}
+	return string(runes[:max]) + "..."
+}
@@ -0,0 +1,102 @@
+package apisTemplate
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ExtractSaveResponse 根据模板中的 save_response 配置，从响应中提取变量
+// saveConfig 形如 {"token": "response.token", "access_control_id": "response.data.id"}
+// 返回 变量名 -> 字符串值 的映射，任一路径取值失败都会返回错误
+func ExtractSaveResponse(resp *APIResponse, saveConfig map[string]string) (map[string]string, error) {
+	values, err := ExtractSaveResponseValues(resp, saveConfig)
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string]string, len(values))
+	for name, value := range values {
+		result[name] = stringifyValue(value)
+	}
+	return result, nil
+}
+
+// ExtractSaveResponseValues 与 ExtractSaveResponse 相同，但保留取到的 JSON 原始类型
+func ExtractSaveResponseValues(resp *APIResponse, saveConfig map[string]string) (map[string]interface{}, error) {
+	result := make(map[string]interface{})
+	if resp == nil || len(saveConfig) == 0 {
+		return result, nil
+	}
+
+	document, err := resp.document()
+	if err != nil {
+		return nil, fmt.Errorf("提取变量失败: %v", err)
+	}
+	for name, path := range saveConfig {
+		value, err := ExtractValue(document, path)
+		if err != nil {
+			return nil, fmt.Errorf("提取变量 '%s' 失败: %v", name, err)
+		}
+		result[name] = value
+	}
+	return result, nil
+}
+
+// ExtractValue 按路径从解析后的 JSON 中取值
+// 路径使用 "." 分隔，可选 "response." 前缀，数组下标支持 "items.0" 和 "items[0]" 两种写法
+func ExtractValue(body interface{}, path string) (interface{}, error) {
+	path = strings.TrimSpace(path)
+	path = strings.TrimPrefix(path, "response")
+	path = strings.TrimPrefix(path, ".")
+	if path == "" {
+		return body, nil
+	}
+
+	// 把 items[0] 统一转换为 items.0
+	path = strings.ReplaceAll(path, "[", ".")
+	path = strings.ReplaceAll(path, "]", "")
+
+	current := body
+	for _, segment := range strings.Split(path, ".") {
+		if segment == "" {
+			continue
+		}
+		switch node := current.(type) {
+		case map[string]interface{}:
+			val, exists := node[segment]
+			if !exists {
+				return nil, fmt.Errorf("路径 '%s' 中字段 '%s' 不存在", path, segment)
+			}
+			current = val
+		case []interface{}:
+			index, err := strconv.Atoi(segment)
+			if err != nil {
+				return nil, fmt.Errorf("路径 '%s' 中 '%s' 不是有效的数组下标", path, segment)
+			}
+			if index < 0 || index >= len(node) {
+				return nil, fmt.Errorf("路径 '%s' 中数组下标 %d 越界 (长度 %d)", path, index, len(node))
+			}
+			current = node[index]
+		default:
+			return nil, fmt.Errorf("路径 '%s' 在 '%s' 处无法继续向下取值", path, segment)
+		}
+	}
+	return current, nil
+}
+
+// stringifyValue 将 JSON 值转换为可用于占位符替换的字符串
+func stringifyValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	default:
+		bytes, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprintf("%v", v)
+		}
+		return string(bytes)
+	}
+}
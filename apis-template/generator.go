@@ -1,7 +1,12 @@
 package apisTemplate
 
 import (
+	"bytes"
 	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
 	"strings"
 )
 
@@ -10,9 +15,43 @@ import (
 type Param struct {
 	Key   string `json:"key"`
 	Value string `json:"value"`
+	// Raw 非字符串参数的 JSON 原文（数字、布尔、对象、数组、null），字符串参数为空
+	// 占位符独占 data / query 中的整个字符串时按该类型替换，其余位置使用 Value 的文本形式
+	Raw json.RawMessage `json:"-"`
 }
 
+// UnmarshalJSON 支持任意 JSON 类型的 value，非字符串值同时保留原始类型
+func (p *Param) UnmarshalJSON(data []byte) error {
+	var aux struct {
+		Key   string          `json:"key"`
+		Value json.RawMessage `json:"value"`
+	}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	p.Key = aux.Key
+	p.Value = ""
+	p.Raw = nil
+	if len(aux.Value) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(aux.Value, &p.Value); err == nil {
+		return nil
+	}
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, aux.Value); err != nil {
+		return err
+	}
+	p.Value = compact.String()
+	p.Raw = json.RawMessage(compact.Bytes())
+	return nil
+}
+
+// placeholderPattern 匹配 {name} 形式的占位符
+var placeholderPattern = regexp.MustCompile(`\{[A-Za-z_][A-Za-z0-9_.\-]*\}`)
+
 // GenerateRequest 根据 API 模板和参数列表，生成最终用于发送的 APIRequest 对象
+// 替换完成后仍存在未解析的占位符时返回错误，避免把 {name} 原样发送给服务器
 func GenerateRequest(template APIRequest, params []Param) (APIRequest, error) {
 	// 1. 深拷贝 (Deep Copy)
 	var newReq APIRequest
@@ -24,11 +63,21 @@ func GenerateRequest(template APIRequest, params []Param) (APIRequest, error) {
 		return newReq, err
 	}
 
-	// 2. 替换 URL 中的占位符
-	newReq.URL = replaceString(newReq.URL, params)
+	// 2. 替换 URL 中的占位符（路径与查询字符串中的值会进行 URL 编码）
+	newReq.URL = replaceURL(newReq.URL, params)
 
-	// 3. 替换 Data (Body) 中的占位符
+	// 3. 替换 Headers 中的占位符（如 "Bearer {token}"）
+	for k, v := range newReq.Headers {
+		newReq.Headers[k] = ReplaceString(v, params)
+	}
+
+	// 4. 替换 Query 与 Data (Body) 中的占位符
 	// Data 是 map[string]interface{}，可能包含嵌套结构，需要递归处理
+	if newReq.Query != nil {
+		if m, ok := resolveData(newReq.Query, params).(map[string]interface{}); ok {
+			newReq.Query = m
+		}
+	}
 	if newReq.Data != nil {
 		processedData := resolveData(newReq.Data, params)
 		// 断言回 map[string]interface{}
@@ -37,15 +86,34 @@ func GenerateRequest(template APIRequest, params []Param) (APIRequest, error) {
 		}
 	}
 
+	// 5. 替换原始请求体与上传文件路径中的占位符
+	newReq.Body = ReplaceString(newReq.Body, params)
+	for field, path := range newReq.Files {
+		newReq.Files[field] = ReplaceString(path, params)
+	}
+
+	// 6. 检查未解析的占位符
+	if missing := unresolvedPlaceholders(newReq); len(missing) > 0 {
+		return newReq, fmt.Errorf("存在未解析的占位符: %s", strings.Join(missing, ", "))
+	}
+
 	return newReq, nil
 }
 
-// =======================================================
-// 私有辅助函数 (Helper Functions)
-// =======================================================
+// BuildURL 返回拼接了 Query 参数的完整 URL，数组值展开为同名的多个参数
+func BuildURL(req APIRequest) string {
+	if len(req.Query) == 0 {
+		return req.URL
+	}
+	separator := "?"
+	if strings.Contains(req.URL, "?") {
+		separator = "&"
+	}
+	return req.URL + separator + formValues(req.Query).Encode()
+}
 
-// replaceString 简单的字符串替换
-func replaceString(str string, params []Param) string {
+// ReplaceString 将字符串中所有出现的参数 Key 替换为 Value
+func ReplaceString(str string, params []Param) string {
 	if str == "" {
 		return ""
 	}
@@ -57,12 +125,118 @@ func replaceString(str string, params []Param) string {
 	return result
 }
 
+// ReplaceEscaped 替换占位符，替换值先经过 escape 编码（如 URL 编码、shell 引用）
+func ReplaceEscaped(str string, params []Param, escape func(string) string) string {
+	result := str
+	for _, p := range params {
+		result = strings.ReplaceAll(result, p.Key, escape(p.Value))
+	}
+	return result
+}
+
+// FindPlaceholders 收集字符串中所有 {name} 形式的占位符（去重并排序），用于检查替换后仍未解析的占位符
+func FindPlaceholders(values ...string) []string {
+	found := make(map[string]bool)
+	for _, value := range values {
+		for _, match := range placeholderPattern.FindAllString(value, -1) {
+			found[match] = true
+		}
+	}
+
+	missing := make([]string, 0, len(found))
+	for key := range found {
+		missing = append(missing, key)
+	}
+	sort.Strings(missing)
+	return missing
+}
+
+// =======================================================
+// 私有辅助函数 (Helper Functions)
+// =======================================================
+
+// replaceURL 替换 URL 中的占位符
+// scheme 与 host 部分原样替换；路径部分的值按路径编码（保留 /），查询字符串部分的值按查询参数编码
+func replaceURL(rawURL string, params []Param) string {
+	offset := 0
+	if i := strings.Index(rawURL, "://"); i >= 0 {
+		offset = i + 3
+	}
+	pathStart, queryStart := len(rawURL), len(rawURL)
+	if i := strings.IndexAny(rawURL[offset:], "/?#"); i >= 0 {
+		pathStart = offset + i
+	}
+	if i := strings.IndexAny(rawURL[pathStart:], "?#"); i >= 0 {
+		queryStart = pathStart + i
+	}
+
+	escapePath := func(value string) string {
+		return strings.ReplaceAll(url.PathEscape(value), "%2F", "/")
+	}
+	return ReplaceString(rawURL[:pathStart], params) +
+		ReplaceEscaped(rawURL[pathStart:queryStart], params, escapePath) +
+		ReplaceEscaped(rawURL[queryStart:], params, url.QueryEscape)
+}
+
+// unresolvedPlaceholders 收集请求中所有未被替换的占位符（去重并排序）
+func unresolvedPlaceholders(req APIRequest) []string {
+	var values []string
+	collect := func(str string) {
+		values = append(values, str)
+	}
+
+	collect(req.URL)
+	for _, v := range req.Headers {
+		collect(v)
+	}
+	collectData(req.Query, collect)
+	collectData(req.Data, collect)
+	collect(req.Body)
+	for _, path := range req.Files {
+		collect(path)
+	}
+	return FindPlaceholders(values...)
+}
+
+// collectData 递归遍历数据中的所有字符串
+func collectData(input interface{}, collect func(string)) {
+	switch v := input.(type) {
+	case string:
+		collect(v)
+	case map[string]interface{}:
+		for _, val := range v {
+			collectData(val, collect)
+		}
+	case []interface{}:
+		for _, val := range v {
+			collectData(val, collect)
+		}
+	}
+}
+
+// findParam 返回第一个 Key 与 key 相同的参数（排在前面的参数优先生效）
+func findParam(params []Param, key string) (Param, bool) {
+	for _, p := range params {
+		if p.Key == key {
+			return p, true
+		}
+	}
+	return Param{}, false
+}
+
 // resolveData 递归遍历任意类型的数据，查找并替换字符串中的占位符
 func resolveData(input interface{}, params []Param) interface{} {
 	switch v := input.(type) {
 	case string:
-		// 如果是字符串，直接尝试替换
-		return replaceString(v, params)
+		// 字符串恰好是一个占位符且参数带有类型时，保留参数的 JSON 类型
+		if p, ok := findParam(params, v); ok && p.Raw != nil {
+			var typed interface{}
+			if err := json.Unmarshal(p.Raw, &typed); err == nil {
+				return typed
+			}
+		}
+		// 否则直接尝试替换
+		return ReplaceString(v, params)
 
 	case map[string]interface{}:
 		// 如果是 Map (JSON Object)，递归处理每一个 Value
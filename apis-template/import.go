@@ -0,0 +1,204 @@
+package apisTemplate
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// ImportResult 导入生成的 API 模板与骨架用例
+type ImportResult struct {
+	Templates APITemplates
+	Cases     []TestCase // 每个接口一个骨架用例，按导入顺序排列
+	Warnings  []string   // 无法完整转换的内容
+}
+
+// MergeResult 合并导入结果到已有模板文件的统计
+type MergeResult struct {
+	Added    []string // 新增的模板名
+	Replaced []string // 覆盖的模板名（force）
+	Skipped  []string // 已存在而跳过的模板名
+}
+
+// MergeAPITemplates 将导入的模板合并到已有的模板文件（文件不存在时新建）
+// 已存在的模板名默认保留原内容并跳过，force 为 true 时覆盖
+// 只修改新增和覆盖的模板，其余模板的内容、顺序、格式以及未知字段保持原样
+func MergeAPITemplates(filePath string, imported APITemplates, force bool) (*MergeResult, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("读取API模板文件失败: %v", err)
+	}
+	entries, lastEnd, err := scanRawEntries(data)
+	if err != nil {
+		return nil, fmt.Errorf("解析API模板文件失败: %v", err)
+	}
+
+	result := &MergeResult{}
+	var edits []rawEdit
+	added := make(APITemplates)
+	for _, key := range sortedTemplateKeys(imported) {
+		entry, exists := entries[key]
+		switch {
+		case !exists:
+			result.Added = append(result.Added, key)
+			added[key] = imported[key]
+			continue
+		case force:
+			result.Replaced = append(result.Replaced, key)
+		default:
+			result.Skipped = append(result.Skipped, key)
+			continue
+		}
+		value, err := marshalIndented(imported[key], "    ")
+		if err != nil {
+			return nil, err
+		}
+		edits = append(edits, rawEdit{start: entry.start, end: entry.end, text: value})
+	}
+
+	if len(result.Added)+len(result.Replaced) == 0 {
+		return result, nil
+	}
+	if len(entries) == 0 {
+		return result, SaveAPITemplates(filePath, added)
+	}
+
+	// 新增的模板追加在最后一个模板之后
+	var insert bytes.Buffer
+	for _, key := range sortedTemplateKeys(added) {
+		name, err := marshalIndented(key, "")
+		if err != nil {
+			return nil, err
+		}
+		value, err := marshalIndented(added[key], "    ")
+		if err != nil {
+			return nil, err
+		}
+		insert.WriteString(",\n    " + name + ": " + value)
+	}
+	if insert.Len() > 0 {
+		edits = append(edits, rawEdit{start: lastEnd, end: lastEnd, text: insert.String()})
+	}
+
+	// 从后往前修改，前面的偏移量不受影响
+	sort.SliceStable(edits, func(i, j int) bool { return edits[i].start > edits[j].start })
+	for _, edit := range edits {
+		data = append(data[:edit.start:edit.start], append([]byte(edit.text), data[edit.end:]...)...)
+	}
+	if err := os.WriteFile(filePath, data, 0644); err != nil {
+		return nil, fmt.Errorf("写入文件失败: %v", err)
+	}
+	return result, nil
+}
+
+// rawEntry JSON 对象中一个值在原文中的位置
+type rawEntry struct {
+	start, end int
+}
+
+// rawEdit 将原文 [start, end) 替换为 text
+type rawEdit struct {
+	start, end int
+	text       string
+}
+
+// scanRawEntries 扫描根节点为对象的 JSON 原文，返回每个键对应的值的位置，以及最后一个值的结束位置
+// 内容为空时返回空结果
+func scanRawEntries(data []byte) (map[string]rawEntry, int, error) {
+	entries := make(map[string]rawEntry)
+	if len(bytes.TrimSpace(data)) == 0 {
+		return entries, 0, nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	if token, err := decoder.Token(); err != nil || token != json.Delim('{') {
+		return nil, 0, fmt.Errorf("根节点不是 JSON 对象")
+	}
+	lastEnd := 0
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, 0, err
+		}
+		key, _ := token.(string)
+		var value json.RawMessage
+		if err := decoder.Decode(&value); err != nil {
+			return nil, 0, err
+		}
+		lastEnd = int(decoder.InputOffset())
+		entries[key] = rawEntry{start: lastEnd - len(value), end: lastEnd}
+	}
+	if _, err := decoder.Token(); err != nil {
+		return nil, 0, err
+	}
+	return entries, lastEnd, nil
+}
+
+// marshalIndented 按模板文件的格式（4 空格缩进，不转义 HTML 字符）序列化，prefix 为每个续行的前缀
+func marshalIndented(value interface{}, prefix string) (string, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent(prefix, "    ")
+	if err := encoder.Encode(value); err != nil {
+		return "", fmt.Errorf("序列化失败: %v", err)
+	}
+	return strings.TrimRight(buf.String(), "\n"), nil
+}
+
+// AppendTestCases 将用例追加到已有的用例文件末尾（文件不存在时新建），已有用例保持原样
+func AppendTestCases(filePath string, cases []TestCase) error {
+	var existing []json.RawMessage
+	if data, err := os.ReadFile(filePath); err == nil {
+		if err := json.Unmarshal(data, &existing); err != nil {
+			return fmt.Errorf("解析用例文件失败: %v", err)
+		}
+	} else if !os.IsNotExist(err) {
+		return fmt.Errorf("读取用例文件失败: %v", err)
+	}
+	if len(existing) == 0 {
+		return SaveTestCases(filePath, cases)
+	}
+
+	all := make([]interface{}, 0, len(existing)+len(cases))
+	for _, raw := range existing {
+		all = append(all, raw)
+	}
+	for _, c := range cases {
+		all = append(all, c)
+	}
+	return writeJSONFile(filePath, all)
+}
+
+// templateKeyPart 将名称转换为模板名的一部分：保留字母、数字（含中文），其他字符替换为下划线
+func templateKeyPart(name string) string {
+	key := strings.Trim(regexp.MustCompile(`[^\p{L}\p{N}]+`).ReplaceAllString(strings.ToLower(name), "_"), "_")
+	if key == "" {
+		return "request"
+	}
+	return key
+}
+
+// uniqueTemplateKey 重名时追加序号
+func uniqueTemplateKey(templates APITemplates, key string) string {
+	unique := key
+	for i := 2; ; i++ {
+		if _, exists := templates[unique]; !exists {
+			return unique
+		}
+		unique = fmt.Sprintf("%s_%d", key, i)
+	}
+}
+
+func sortedTemplateKeys(templates APITemplates) []string {
+	keys := make([]string, 0, len(templates))
+	for key := range templates {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,161 @@
+package apisTemplate
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// jsonPathSegment JSONPath 中的一级选择器
+type jsonPathSegment struct {
+	key      string // 对象字段名
+	index    int    // 数组下标（支持负数，-1 表示最后一个）
+	isIndex  bool   // 是否为数组下标
+	wildcard bool   // [*] 或 .*，匹配所有子元素
+}
+
+// EvaluateJSONPath 在解析后的 JSON 上计算 JSONPath 表达式
+// 支持 $、.key、['key']、[n]（含负数下标）、[*] / .* 通配
+// 不含通配符时返回单个值；含通配符时返回所有匹配值组成的数组
+func EvaluateJSONPath(document interface{}, path string) (interface{}, error) {
+	segments, err := parseJSONPath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	current := []interface{}{document}
+	multiple := false
+	for _, seg := range segments {
+		var next []interface{}
+		for _, node := range current {
+			values, err := seg.apply(node, path, !multiple)
+			if err != nil {
+				return nil, err
+			}
+			next = append(next, values...)
+		}
+		if seg.wildcard {
+			multiple = true
+		}
+		current = next
+	}
+
+	if multiple {
+		if current == nil {
+			current = []interface{}{}
+		}
+		return current, nil
+	}
+	return current[0], nil
+}
+
+// apply 对单个节点应用选择器
+// strict 为 true 时字段不存在、下标越界返回错误；通配之后的选择器只收集存在的值
+func (seg jsonPathSegment) apply(node interface{}, path string, strict bool) ([]interface{}, error) {
+	switch {
+	case seg.wildcard:
+		switch val := node.(type) {
+		case []interface{}:
+			return val, nil
+		case map[string]interface{}:
+			values := make([]interface{}, 0, len(val))
+			for _, key := range sortedKeys(val) {
+				values = append(values, val[key])
+			}
+			return values, nil
+		}
+	case seg.isIndex:
+		if arr, ok := node.([]interface{}); ok {
+			index := seg.index
+			if index < 0 {
+				index += len(arr)
+			}
+			if index >= 0 && index < len(arr) {
+				return []interface{}{arr[index]}, nil
+			}
+			if strict {
+				return nil, fmt.Errorf("路径 '%s' 中数组下标 %d 越界 (长度 %d)", path, seg.index, len(arr))
+			}
+			return nil, nil
+		}
+	default:
+		if obj, ok := node.(map[string]interface{}); ok {
+			if val, exists := obj[seg.key]; exists {
+				return []interface{}{val}, nil
+			}
+			if strict {
+				return nil, fmt.Errorf("路径 '%s' 中字段 '%s' 不存在", path, seg.key)
+			}
+			return nil, nil
+		}
+	}
+
+	if strict {
+		return nil, fmt.Errorf("路径 '%s' 在 '%s' 处无法继续向下取值", path, seg)
+	}
+	return nil, nil
+}
+
+func (seg jsonPathSegment) String() string {
+	switch {
+	case seg.wildcard:
+		return "*"
+	case seg.isIndex:
+		return strconv.Itoa(seg.index)
+	default:
+		return seg.key
+	}
+}
+
+// parseJSONPath 将 JSONPath 表达式拆分为选择器列表
+func parseJSONPath(path string) ([]jsonPathSegment, error) {
+	expr := strings.TrimSpace(path)
+	if !strings.HasPrefix(expr, "$") {
+		return nil, fmt.Errorf("JSONPath '%s' 必须以 $ 开头", path)
+	}
+	expr = expr[1:]
+
+	var segments []jsonPathSegment
+	for len(expr) > 0 {
+		switch expr[0] {
+		case '.':
+			expr = expr[1:]
+			end := strings.IndexAny(expr, ".[")
+			if end < 0 {
+				end = len(expr)
+			}
+			key := expr[:end]
+			if key == "" {
+				return nil, fmt.Errorf("JSONPath '%s' 中存在空字段名", path)
+			}
+			if key == "*" {
+				segments = append(segments, jsonPathSegment{wildcard: true})
+			} else {
+				segments = append(segments, jsonPathSegment{key: key})
+			}
+			expr = expr[end:]
+		case '[':
+			end := strings.Index(expr, "]")
+			if end < 0 {
+				return nil, fmt.Errorf("JSONPath '%s' 中 [ 未闭合", path)
+			}
+			content := strings.TrimSpace(expr[1:end])
+			expr = expr[end+1:]
+			switch {
+			case content == "*":
+				segments = append(segments, jsonPathSegment{wildcard: true})
+			case len(content) >= 2 && (content[0] == '\'' || content[0] == '"') && content[len(content)-1] == content[0]:
+				segments = append(segments, jsonPathSegment{key: content[1 : len(content)-1]})
+			default:
+				index, err := strconv.Atoi(content)
+				if err != nil {
+					return nil, fmt.Errorf("JSONPath '%s' 中 '[%s]' 不是有效的数组下标", path, content)
+				}
+				segments = append(segments, jsonPathSegment{index: index, isIndex: true})
+			}
+		default:
+			return nil, fmt.Errorf("JSONPath '%s' 格式错误: 无法解析 '%s'", path, expr)
+		}
+	}
+	return segments, nil
+}
@@ -0,0 +1,589 @@
+package apisTemplate
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// openAPIMethods 按固定顺序遍历的 HTTP 方法
+var openAPIMethods = []string{"get", "post", "put", "patch", "delete", "head", "options", "trace"}
+
+// maxSampleDepth 由 schema 生成示例数据的最大嵌套深度，避免循环引用
+const maxSampleDepth = 8
+
+// openAPISpec 解析后的 OpenAPI 3.x / Swagger 2.0 文档
+type openAPISpec struct {
+	root     map[string]interface{}
+	swagger2 bool
+	result   *ImportResult
+}
+
+// ImportOpenAPI 将 OpenAPI 3.x 或 Swagger 2.0 文档（JSON / YAML）转换为 API 模板
+// 模板名取自 operationId；路径与必填查询参数转换为 {占位符}；请求体示例或 schema 默认值转换为 data；
+// 认证方案转换为请求头；同时为每个接口生成断言文档中成功状态码的骨架用例
+func ImportOpenAPI(data []byte) (*ImportResult, error) {
+	root, err := parseSpecDocument(data)
+	if err != nil {
+		return nil, err
+	}
+	spec := &openAPISpec{
+		root:   root,
+		result: &ImportResult{Templates: make(APITemplates)},
+	}
+	switch {
+	case stringField(root, "openapi") != "":
+	case stringField(root, "swagger") != "":
+		spec.swagger2 = true
+	default:
+		return nil, fmt.Errorf("不是有效的 OpenAPI / Swagger 文档: 缺少 openapi 或 swagger 字段")
+	}
+
+	paths := mapField(root, "paths")
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("文档中没有 paths")
+	}
+	baseURL, basePath := spec.server()
+	for _, path := range sortedKeys(paths) {
+		item := spec.deref(paths[path])
+		for _, method := range openAPIMethods {
+			op, ok := item[method].(map[string]interface{})
+			if !ok {
+				continue
+			}
+			spec.importOperation(baseURL, basePath, path, method, item, op)
+		}
+	}
+	return spec.result, nil
+}
+
+// parseSpecDocument 解析 JSON 或 YAML 文档
+func parseSpecDocument(data []byte) (map[string]interface{}, error) {
+	var root map[string]interface{}
+	if err := json.Unmarshal(data, &root); err == nil {
+		return root, nil
+	}
+	var doc interface{}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("解析文档失败: %v", err)
+	}
+	root, ok := normalizeYAML(doc).(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("解析文档失败: 根节点不是对象")
+	}
+	return root, nil
+}
+
+// normalizeYAML 将 yaml.v2 解析出的 map[interface{}]interface{} 转换为 JSON 兼容的结构
+func normalizeYAML(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			m[fmt.Sprintf("%v", key)] = normalizeYAML(item)
+		}
+		return m
+	case []interface{}:
+		for i, item := range v {
+			v[i] = normalizeYAML(item)
+		}
+		return v
+	case int:
+		return float64(v)
+	default:
+		return v
+	}
+}
+
+// server 返回服务地址（作为 {base_url} 参数的默认值）与需要拼接在路径前的 base path
+func (s *openAPISpec) server() (string, string) {
+	if s.swagger2 {
+		basePath := strings.TrimSuffix(stringField(s.root, "basePath"), "/")
+		host := stringField(s.root, "host")
+		if host == "" {
+			return "", basePath
+		}
+		scheme := "https"
+		if schemes, ok := s.root["schemes"].([]interface{}); ok && len(schemes) > 0 {
+			scheme = fmt.Sprintf("%v", schemes[0])
+		}
+		return scheme + "://" + host, basePath
+	}
+
+	servers, _ := s.root["servers"].([]interface{})
+	if len(servers) == 0 {
+		return "", ""
+	}
+	server, _ := servers[0].(map[string]interface{})
+	rawURL := stringField(server, "url")
+	// 服务器变量替换为默认值
+	for name, variable := range mapField(server, "variables") {
+		if def, ok := variable.(map[string]interface{})["default"]; ok {
+			rawURL = strings.ReplaceAll(rawURL, "{"+name+"}", fmt.Sprintf("%v", def))
+		}
+	}
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return "", ""
+	}
+	basePath := strings.TrimSuffix(parsed.Path, "/")
+	if parsed.Host == "" {
+		return "", basePath
+	}
+	return parsed.Scheme + "://" + parsed.Host, basePath
+}
+
+// importOperation 转换单个接口
+func (s *openAPISpec) importOperation(baseURL, basePath, path, method string, item, op map[string]interface{}) {
+	key := s.templateKey(op, method, path)
+	req := APIRequest{
+		URL:    "{base_url}" + basePath + path,
+		Method: method,
+	}
+	examples := map[string]interface{}{"base_url": baseURL}
+
+	// 参数：路径参数本身就是 {name}；查询参数与必填的请求头转换为占位符，默认值写入骨架用例的参数；
+	// 可选请求头有默认值时直接使用默认值
+	var formParams []map[string]interface{}
+	for _, param := range s.parameters(item, op) {
+		name := stringField(param, "name")
+		in := stringField(param, "in")
+		required, _ := param["required"].(bool)
+		sample, hasSample := s.parameterSample(param)
+		switch in {
+		case "path":
+			if hasSample {
+				examples[name] = sample
+			}
+		case "query", "header":
+			var value interface{}
+			if required || in == "query" {
+				value = "{" + name + "}"
+				if hasSample {
+					examples[name] = sample
+				}
+			} else if hasSample {
+				value = sample
+			} else {
+				continue
+			}
+			if in == "query" {
+				if req.Query == nil {
+					req.Query = make(map[string]interface{})
+				}
+				req.Query[name] = value
+			} else {
+				if req.Headers == nil {
+					req.Headers = make(map[string]string)
+				}
+				req.Headers[name] = stringifyValue(value)
+			}
+		case "body":
+			s.applyBody(key, &req, "application/json", mapField(param, "schema"), nil)
+		case "formData":
+			formParams = append(formParams, param)
+		}
+	}
+	if len(formParams) > 0 {
+		s.applySwaggerForm(&req, op, formParams)
+	}
+	if body := s.deref(op["requestBody"]); !s.swagger2 && body != nil {
+		s.applyRequestBody(key, &req, body)
+	}
+
+	// 认证占位符（如 {token}）通常来自 save_response 保存的运行期变量，不写入用例参数，以免覆盖变量
+	credential := s.applySecurity(&req, op)
+
+	s.result.Templates[key] = req
+	s.result.Cases = append(s.result.Cases, TestCase{
+		Name:      s.caseName(key, op),
+		APIConfig: TestCaseConfig{Template: key, Params: templateParams(req, examples, credential)},
+		Expect:    s.successExpect(op),
+	})
+}
+
+// templateKey 使用 operationId 作为模板名，缺失时由方法与路径生成；重名时追加序号
+func (s *openAPISpec) templateKey(op map[string]interface{}, method, path string) string {
+	key := stringField(op, "operationId")
+	if key == "" {
+		key = method + "_" + strings.Trim(regexp.MustCompile(`[^A-Za-z0-9]+`).ReplaceAllString(path, "_"), "_")
+		s.warn("%s %s 缺少 operationId，模板名使用 %s", strings.ToUpper(method), path, key)
+	}
+	return uniqueTemplateKey(s.result.Templates, key)
+}
+
+// parameters 合并路径级与接口级参数，接口级参数覆盖同名同位置的路径级参数
+func (s *openAPISpec) parameters(item, op map[string]interface{}) []map[string]interface{} {
+	var params []map[string]interface{}
+	index := make(map[string]int)
+	for _, source := range []map[string]interface{}{item, op} {
+		list, _ := source["parameters"].([]interface{})
+		for _, raw := range list {
+			param := s.deref(raw)
+			if param == nil {
+				continue
+			}
+			id := stringField(param, "in") + ":" + stringField(param, "name")
+			if i, exists := index[id]; exists {
+				params[i] = param
+				continue
+			}
+			index[id] = len(params)
+			params = append(params, param)
+		}
+	}
+	return params
+}
+
+// parameterSample 返回参数的示例值或默认值
+func (s *openAPISpec) parameterSample(param map[string]interface{}) (interface{}, bool) {
+	if example, ok := param["example"]; ok {
+		return example, true
+	}
+	if schema := s.deref(param["schema"]); schema != nil {
+		if sample, ok := explicitSample(schema); ok {
+			return sample, true
+		}
+	}
+	return explicitSample(param)
+}
+
+// applyRequestBody 转换 OpenAPI 3 的 requestBody，优先使用 JSON，其次表单
+func (s *openAPISpec) applyRequestBody(key string, req *APIRequest, body map[string]interface{}) {
+	content := mapField(body, "content")
+	for _, mediaType := range sortedKeys(content) {
+		if isJSONMediaType(mediaType) {
+			media, _ := content[mediaType].(map[string]interface{})
+			s.applyBody(key, req, mediaType, mapField(media, "schema"), media)
+			return
+		}
+	}
+	for _, mediaType := range []string{"application/x-www-form-urlencoded", "multipart/form-data"} {
+		if media, ok := content[mediaType].(map[string]interface{}); ok {
+			s.applyBody(key, req, mediaType, mapField(media, "schema"), media)
+			return
+		}
+	}
+	if len(content) > 0 {
+		s.warn("%s: 不支持的请求体类型 %s，已忽略", key, strings.Join(sortedKeys(content), ", "))
+	}
+}
+
+// applyBody 由示例或 schema 生成请求体；multipart 中 format: binary 的字段转换为上传文件
+func (s *openAPISpec) applyBody(key string, req *APIRequest, mediaType string, schema, media map[string]interface{}) {
+	sample, ok := mediaExample(media)
+	if !ok {
+		sample = s.sample(schema, 0)
+	}
+	data, isObject := sample.(map[string]interface{})
+	if !isObject {
+		if sample != nil {
+			s.warn("%s: 请求体不是 JSON 对象，已忽略", key)
+		}
+		return
+	}
+
+	switch mediaType {
+	case "application/x-www-form-urlencoded":
+		req.BodyType = BodyTypeForm
+	case "multipart/form-data":
+		req.BodyType = BodyTypeMultipart
+		for name, prop := range mapField(s.deref(schema), "properties") {
+			if stringField(s.deref(prop), "format") == "binary" {
+				delete(data, name)
+				if req.Files == nil {
+					req.Files = make(map[string]string)
+				}
+				req.Files[name] = "{" + name + "}"
+			}
+		}
+	}
+	req.Data = data
+}
+
+// applySwaggerForm 转换 Swagger 2.0 的 formData 参数
+func (s *openAPISpec) applySwaggerForm(req *APIRequest, op map[string]interface{}, params []map[string]interface{}) {
+	req.BodyType = BodyTypeForm
+	consumes, _ := op["consumes"].([]interface{})
+	if consumes == nil {
+		consumes, _ = s.root["consumes"].([]interface{})
+	}
+	for _, c := range consumes {
+		if c == "multipart/form-data" {
+			req.BodyType = BodyTypeMultipart
+		}
+	}
+
+	req.Data = make(map[string]interface{})
+	for _, param := range params {
+		name := stringField(param, "name")
+		if stringField(param, "type") == "file" {
+			req.BodyType = BodyTypeMultipart
+			if req.Files == nil {
+				req.Files = make(map[string]string)
+			}
+			req.Files[name] = "{" + name + "}"
+			continue
+		}
+		req.Data[name] = s.sample(param, 0)
+	}
+}
+
+// applySecurity 将接口（或全局）的第一个认证方案转换为请求头或查询参数，返回使用的占位符
+func (s *openAPISpec) applySecurity(req *APIRequest, op map[string]interface{}) string {
+	requirements, declared := op["security"].([]interface{})
+	if !declared {
+		requirements, _ = s.root["security"].([]interface{})
+	}
+	if len(requirements) == 0 {
+		return ""
+	}
+	requirement, _ := requirements[0].(map[string]interface{})
+	names := sortedKeys(requirement)
+	if len(names) == 0 {
+		return ""
+	}
+
+	definitions := mapField(mapField(s.root, "components"), "securitySchemes")
+	if s.swagger2 {
+		definitions = mapField(s.root, "securityDefinitions")
+	}
+	scheme := s.deref(definitions[names[0]])
+	if scheme == nil {
+		s.warn("未定义的认证方案: %s", names[0])
+		return ""
+	}
+
+	setHeader := func(name, value string) {
+		if req.Headers == nil {
+			req.Headers = make(map[string]string)
+		}
+		req.Headers[name] = value
+	}
+	switch stringField(scheme, "type") {
+	case "http":
+		if strings.EqualFold(stringField(scheme, "scheme"), "basic") {
+			setHeader("Authorization", "Basic {basic_auth}")
+			return "{basic_auth}"
+		}
+		setHeader("Authorization", "Bearer {token}")
+		return "{token}"
+	case "basic":
+		setHeader("Authorization", "Basic {basic_auth}")
+		return "{basic_auth}"
+	case "oauth2", "openIdConnect":
+		setHeader("Authorization", "Bearer {token}")
+		return "{token}"
+	case "apiKey":
+		name := stringField(scheme, "name")
+		placeholder := "{" + placeholderName(name) + "}"
+		switch stringField(scheme, "in") {
+		case "query":
+			if req.Query == nil {
+				req.Query = make(map[string]interface{})
+			}
+			req.Query[name] = placeholder
+		case "cookie":
+			setHeader("Cookie", name+"="+placeholder)
+		default:
+			setHeader(name, placeholder)
+		}
+		return placeholder
+	default:
+		s.warn("不支持的认证方案类型: %s", stringField(scheme, "type"))
+		return ""
+	}
+}
+
+// successExpect 断言文档中第一个 2xx 状态码；只声明了 2XX 或没有成功响应时断言 2xx
+func (s *openAPISpec) successExpect(op map[string]interface{}) ExpectConfig {
+	responses := mapField(op, "responses")
+	for _, code := range sortedKeys(responses) {
+		if status, err := strconv.Atoi(code); err == nil && status >= 200 && status < 300 {
+			return ExpectConfig{Status: status}
+		}
+	}
+	return ExpectConfig{StatusIn: []string{"2xx"}}
+}
+
+func (s *openAPISpec) caseName(key string, op map[string]interface{}) string {
+	if summary := stringField(op, "summary"); summary != "" {
+		return summary
+	}
+	return key
+}
+
+// sample 由 schema 生成示例数据：依次使用 example、default、enum 第一项，对象与数组递归生成
+// 没有示例的标量字段使用零值，保留请求体的结构
+func (s *openAPISpec) sample(rawSchema interface{}, depth int) interface{} {
+	schema := s.deref(rawSchema)
+	if schema == nil || depth > maxSampleDepth {
+		return nil
+	}
+	if sample, ok := explicitSample(schema); ok {
+		return sample
+	}
+
+	for _, combinator := range []string{"oneOf", "anyOf"} {
+		if options, ok := schema[combinator].([]interface{}); ok && len(options) > 0 {
+			return s.sample(options[0], depth+1)
+		}
+	}
+	if parts, ok := schema["allOf"].([]interface{}); ok {
+		merged := make(map[string]interface{})
+		for _, part := range parts {
+			if obj, ok := s.sample(part, depth+1).(map[string]interface{}); ok {
+				for k, v := range obj {
+					merged[k] = v
+				}
+			}
+		}
+		return merged
+	}
+
+	switch schemaType(schema) {
+	case "object":
+		obj := make(map[string]interface{})
+		for name, prop := range mapField(schema, "properties") {
+			obj[name] = s.sample(prop, depth+1)
+		}
+		return obj
+	case "array":
+		if item := s.sample(schema["items"], depth+1); item != nil {
+			return []interface{}{item}
+		}
+		return []interface{}{}
+	case "integer", "number":
+		return float64(0)
+	case "boolean":
+		return false
+	case "string", "file":
+		return ""
+	default:
+		return nil
+	}
+}
+
+// deref 解析本地 $ref（#/components/...、#/definitions/...），返回对象节点
+func (s *openAPISpec) deref(value interface{}) map[string]interface{} {
+	node, _ := value.(map[string]interface{})
+	for i := 0; node != nil && i < maxSampleDepth; i++ {
+		ref := stringField(node, "$ref")
+		if ref == "" {
+			return node
+		}
+		if !strings.HasPrefix(ref, "#/") {
+			s.warn("不支持外部引用: %s", ref)
+			return nil
+		}
+		var current interface{} = s.root
+		for _, token := range strings.Split(ref[2:], "/") {
+			token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+			obj, _ := current.(map[string]interface{})
+			current = obj[token]
+		}
+		node, _ = current.(map[string]interface{})
+		if node == nil {
+			s.warn("无法解析引用: %s", ref)
+		}
+	}
+	return node
+}
+
+func (s *openAPISpec) warn(format string, args ...interface{}) {
+	message := fmt.Sprintf(format, args...)
+	for _, existing := range s.result.Warnings {
+		if existing == message {
+			return
+		}
+	}
+	s.result.Warnings = append(s.result.Warnings, message)
+}
+
+// templateParams 为模板中的每个占位符（exclude 除外）生成参数，有示例值时填入示例值
+func templateParams(req APIRequest, examples map[string]interface{}, exclude string) []Param {
+	names := unresolvedPlaceholders(req)
+	params := make([]Param, 0, len(names))
+	for _, placeholder := range names {
+		if placeholder == exclude {
+			continue
+		}
+		name := strings.Trim(placeholder, "{}")
+		param := Param{Key: placeholder}
+		if example, ok := examples[name]; ok && example != nil {
+			param.Value = stringifyValue(example)
+		}
+		params = append(params, param)
+	}
+	return params
+}
+
+// mediaExample 返回 media type 中的 example 或第一个 examples
+func mediaExample(media map[string]interface{}) (interface{}, bool) {
+	if example, ok := media["example"]; ok {
+		return example, true
+	}
+	examples := mapField(media, "examples")
+	for _, name := range sortedKeys(examples) {
+		if example, ok := examples[name].(map[string]interface{}); ok {
+			if value, ok := example["value"]; ok {
+				return value, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// explicitSample 返回 schema 中显式给出的 example、default 或 enum 第一项
+func explicitSample(schema map[string]interface{}) (interface{}, bool) {
+	for _, field := range []string{"example", "default", "x-example"} {
+		if value, ok := schema[field]; ok {
+			return value, true
+		}
+	}
+	if enum, ok := schema["enum"].([]interface{}); ok && len(enum) > 0 {
+		return enum[0], true
+	}
+	return nil, false
+}
+
+func schemaType(schema map[string]interface{}) string {
+	switch t := schema["type"].(type) {
+	case string:
+		return t
+	case []interface{}:
+		for _, item := range t {
+			if name, ok := item.(string); ok && name != "null" {
+				return name
+			}
+		}
+	}
+	if _, ok := schema["properties"]; ok {
+		return "object"
+	}
+	return ""
+}
+
+func isJSONMediaType(mediaType string) bool {
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// placeholderName 将请求头等名称转换为占位符名称，如 X-API-Key -> x_api_key
+func placeholderName(name string) string {
+	return strings.Trim(regexp.MustCompile(`[^a-z0-9]+`).ReplaceAllString(strings.ToLower(name), "_"), "_")
+}
+
+func stringField(node map[string]interface{}, key string) string {
+	value, _ := node[key].(string)
+	return value
+}
+
+func mapField(node map[string]interface{}, key string) map[string]interface{} {
+	value, _ := node[key].(map[string]interface{})
+	return value
+}
@@ -0,0 +1,77 @@
+package apisTemplate
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// 轮询默认值
+const (
+	DefaultPollIntervalMs = 1000  // 轮询间隔（毫秒）
+	DefaultPollTimeoutMs  = 30000 // 轮询超时（毫秒）
+)
+
+// PollConfig 轮询配置：重复发送同一个请求，直到响应满足 until 或超时
+// until 可以写单个断言 {"path", "op", "value"}、断言数组，或完整的 expect 对象（status、body、assertions 等）
+type PollConfig struct {
+	IntervalMs int           `json:"interval_ms,omitempty"` // 轮询间隔，默认 1000
+	TimeoutMs  int           `json:"timeout_ms,omitempty"`  // 轮询超时，默认 30000
+	Until      *ExpectConfig `json:"until,omitempty"`       // 结束条件，未设置时使用用例的 expect
+}
+
+// UnmarshalJSON 支持 until 的单个断言与断言数组简写
+func (p *PollConfig) UnmarshalJSON(data []byte) error {
+	type plain PollConfig
+	aux := struct {
+		*plain
+		Until json.RawMessage `json:"until"`
+	}{plain: (*plain)(p)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	p.Until = nil
+	if len(aux.Until) == 0 || string(aux.Until) == "null" {
+		return nil
+	}
+
+	var assertions []Assertion
+	if err := json.Unmarshal(aux.Until, &assertions); err == nil {
+		p.Until = &ExpectConfig{Assertions: assertions}
+		return nil
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(aux.Until, &fields); err != nil {
+		return err
+	}
+	if _, isAssertion := fields["path"]; isAssertion {
+		var assertion Assertion
+		if err := json.Unmarshal(aux.Until, &assertion); err != nil {
+			return err
+		}
+		p.Until = &ExpectConfig{Assertions: []Assertion{assertion}}
+		return nil
+	}
+	var expect ExpectConfig
+	if err := json.Unmarshal(aux.Until, &expect); err != nil {
+		return err
+	}
+	p.Until = &expect
+	return nil
+}
+
+// Interval 返回轮询间隔
+func (p PollConfig) Interval() time.Duration {
+	if p.IntervalMs <= 0 {
+		return DefaultPollIntervalMs * time.Millisecond
+	}
+	return time.Duration(p.IntervalMs) * time.Millisecond
+}
+
+// Timeout 返回轮询超时
+func (p PollConfig) Timeout() time.Duration {
+	if p.TimeoutMs <= 0 {
+		return DefaultPollTimeoutMs * time.Millisecond
+	}
+	return time.Duration(p.TimeoutMs) * time.Millisecond
+}
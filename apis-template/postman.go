@@ -0,0 +1,360 @@
+package apisTemplate
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// postmanVariablePattern 匹配 Postman 的 {{var}} 变量
+var postmanVariablePattern = regexp.MustCompile(`\{\{\s*([^{}\s]+)\s*\}\}`)
+
+// postmanCollection Postman v2.1 集合
+type postmanCollection struct {
+	Info struct {
+		Name   string `json:"name"`
+		Schema string `json:"schema"`
+	} `json:"info"`
+	Item []postmanItem `json:"item"`
+	Auth *postmanAuth  `json:"auth"`
+}
+
+// postmanItem 文件夹（含 item）或请求（含 request）
+type postmanItem struct {
+	Name    string          `json:"name"`
+	Item    []postmanItem   `json:"item"`
+	Request json.RawMessage `json:"request"`
+	Auth    *postmanAuth    `json:"auth"`
+}
+
+type postmanRequest struct {
+	Method string          `json:"method"`
+	Header []postmanField  `json:"header"`
+	URL    json.RawMessage `json:"url"`
+	Body   *postmanBody    `json:"body"`
+	Auth   *postmanAuth    `json:"auth"`
+}
+
+type postmanURL struct {
+	Raw      string         `json:"raw"`
+	Query    []postmanField `json:"query"`
+	Variable []postmanField `json:"variable"`
+}
+
+type postmanBody struct {
+	Mode       string         `json:"mode"` // raw, urlencoded, formdata, graphql, file
+	Raw        string         `json:"raw"`
+	URLEncoded []postmanField `json:"urlencoded"`
+	FormData   []postmanField `json:"formdata"`
+	GraphQL    *struct {
+		Query     string `json:"query"`
+		Variables string `json:"variables"`
+	} `json:"graphql"`
+	Options struct {
+		Raw struct {
+			Language string `json:"language"`
+		} `json:"raw"`
+	} `json:"options"`
+}
+
+// postmanField Postman 中的键值对（请求头、查询参数、表单字段、认证参数）
+type postmanField struct {
+	Key      string      `json:"key"`
+	Value    interface{} `json:"value"`
+	Type     string      `json:"type"` // formdata: text / file
+	Src      interface{} `json:"src"`  // formdata 文件路径
+	Disabled bool        `json:"disabled"`
+}
+
+type postmanAuth struct {
+	Type   string         `json:"type"` // noauth, basic, bearer, apikey, oauth2
+	Basic  []postmanField `json:"basic"`
+	Bearer []postmanField `json:"bearer"`
+	APIKey []postmanField `json:"apikey"`
+	OAuth2 []postmanField `json:"oauth2"`
+}
+
+// ImportPostman 将 Postman v2.1 集合转换为 API 模板
+// {{var}} 变量转换为 {var} 占位符，路径变量 :id 转换为 {id}，文件夹名作为模板名前缀（以 . 分隔），
+// 认证（可继承自文件夹或集合）转换为请求头或查询参数
+func ImportPostman(data []byte) (*ImportResult, error) {
+	var collection postmanCollection
+	if err := json.Unmarshal(data, &collection); err != nil {
+		return nil, fmt.Errorf("解析 Postman 集合失败: %v", err)
+	}
+	if collection.Info.Schema != "" && !strings.Contains(collection.Info.Schema, "v2.1") {
+		return nil, fmt.Errorf("仅支持 Postman v2.1 集合，当前为: %s", collection.Info.Schema)
+	}
+
+	importer := &postmanImporter{result: &ImportResult{Templates: make(APITemplates)}}
+	importer.importItems(collection.Item, "", collection.Auth)
+	if len(importer.result.Templates) == 0 {
+		return nil, fmt.Errorf("集合中没有请求")
+	}
+	return importer.result, nil
+}
+
+type postmanImporter struct {
+	result *ImportResult
+}
+
+func (p *postmanImporter) importItems(items []postmanItem, prefix string, auth *postmanAuth) {
+	for _, item := range items {
+		itemAuth := auth
+		if item.Auth != nil {
+			itemAuth = item.Auth
+		}
+		if len(item.Request) == 0 {
+			p.importItems(item.Item, prefix+templateKeyPart(item.Name)+".", itemAuth)
+			continue
+		}
+
+		key := uniqueTemplateKey(p.result.Templates, prefix+templateKeyPart(item.Name))
+		req, err := p.convertRequest(key, item.Request, itemAuth)
+		if err != nil {
+			p.result.Warnings = append(p.result.Warnings, fmt.Sprintf("%s: %v，已跳过", key, err))
+			continue
+		}
+		p.result.Templates[key] = req
+	}
+}
+
+func (p *postmanImporter) convertRequest(key string, raw json.RawMessage, inherited *postmanAuth) (APIRequest, error) {
+	var request postmanRequest
+	// request 可以直接是 URL 字符串
+	var rawURL string
+	if err := json.Unmarshal(raw, &rawURL); err == nil {
+		request.Method = "GET"
+		request.URL, _ = json.Marshal(rawURL)
+	} else if err := json.Unmarshal(raw, &request); err != nil {
+		return APIRequest{}, err
+	}
+
+	req := APIRequest{Method: strings.ToLower(request.Method)}
+	if req.Method == "" {
+		req.Method = "get"
+	}
+
+	// URL：raw 中的查询字符串拆分到 query，路径变量 :id 转换为 {id}
+	var u postmanURL
+	if err := json.Unmarshal(request.URL, &u.Raw); err != nil {
+		if err := json.Unmarshal(request.URL, &u); err != nil {
+			return req, fmt.Errorf("无法解析 URL: %v", err)
+		}
+	}
+	if u.Raw == "" {
+		return req, fmt.Errorf("缺少 URL")
+	}
+	base, rawQuery, _ := strings.Cut(convertPostmanVariables(u.Raw), "?")
+	req.URL = regexp.MustCompile(`/:([A-Za-z_][A-Za-z0-9_]*)`).ReplaceAllString(base, "/{$1}")
+	query := u.Query
+	if query == nil && rawQuery != "" {
+		values, err := url.ParseQuery(rawQuery)
+		if err == nil {
+			for name, items := range values {
+				for _, value := range items {
+					query = append(query, postmanField{Key: name, Value: value})
+				}
+			}
+		}
+	}
+	for _, field := range query {
+		if field.Disabled || field.Key == "" {
+			continue
+		}
+		if req.Query == nil {
+			req.Query = make(map[string]interface{})
+		}
+		addFormValue(req.Query, convertPostmanVariables(field.Key), convertPostmanVariables(fieldString(field.Value)))
+	}
+
+	for _, header := range request.Header {
+		if header.Disabled || header.Key == "" {
+			continue
+		}
+		if req.Headers == nil {
+			req.Headers = make(map[string]string)
+		}
+		req.Headers[header.Key] = convertPostmanVariables(fieldString(header.Value))
+	}
+
+	if request.Body != nil {
+		p.convertBody(key, &req, request.Body)
+	}
+
+	auth := inherited
+	if request.Auth != nil {
+		auth = request.Auth
+	}
+	p.applyAuth(key, &req, auth)
+	return req, nil
+}
+
+// convertBody 转换请求体：JSON 对象转换为 data，表单转换为 form / multipart，其他内容原样作为 body
+func (p *postmanImporter) convertBody(key string, req *APIRequest, body *postmanBody) {
+	switch body.Mode {
+	case "raw":
+		raw := convertPostmanVariables(body.Raw)
+		if strings.TrimSpace(raw) == "" {
+			return
+		}
+		if data, ok := parseJSONObjectBody(raw); ok {
+			req.Data = data
+			return
+		}
+		switch strings.ToLower(body.Options.Raw.Language) {
+		case "xml", "html":
+			req.BodyType = BodyTypeXML
+		default:
+			req.BodyType = BodyTypeRaw
+		}
+		req.Body = raw
+	case "urlencoded":
+		req.BodyType = BodyTypeForm
+		req.Data = make(map[string]interface{})
+		for _, field := range body.URLEncoded {
+			if !field.Disabled && field.Key != "" {
+				addFormValue(req.Data, convertPostmanVariables(field.Key), convertPostmanVariables(fieldString(field.Value)))
+			}
+		}
+	case "formdata":
+		req.BodyType = BodyTypeMultipart
+		req.Data = make(map[string]interface{})
+		for _, field := range body.FormData {
+			if field.Disabled || field.Key == "" {
+				continue
+			}
+			name := convertPostmanVariables(field.Key)
+			if field.Type == "file" {
+				if req.Files == nil {
+					req.Files = make(map[string]string)
+				}
+				src := fieldString(field.Src)
+				if src == "" {
+					src = "{" + placeholderName(name) + "}"
+				}
+				req.Files[name] = convertPostmanVariables(src)
+				continue
+			}
+			addFormValue(req.Data, name, convertPostmanVariables(fieldString(field.Value)))
+		}
+	case "graphql":
+		if body.GraphQL == nil {
+			return
+		}
+		req.Data = map[string]interface{}{"query": convertPostmanVariables(body.GraphQL.Query)}
+		if variables, ok := parseJSONObjectBody(convertPostmanVariables(body.GraphQL.Variables)); ok {
+			req.Data["variables"] = variables
+		}
+	case "":
+	default:
+		p.result.Warnings = append(p.result.Warnings, fmt.Sprintf("%s: 不支持的请求体类型 %s，已忽略", key, body.Mode))
+	}
+}
+
+// applyAuth 将 Postman 认证转换为请求头或查询参数，模板中已有同名请求头时不覆盖
+func (p *postmanImporter) applyAuth(key string, req *APIRequest, auth *postmanAuth) {
+	if auth == nil {
+		return
+	}
+	setHeader := func(name, value string) {
+		if hasHeader(req.Headers, name) {
+			return
+		}
+		if req.Headers == nil {
+			req.Headers = make(map[string]string)
+		}
+		req.Headers[name] = value
+	}
+
+	switch auth.Type {
+	case "", "noauth":
+	case "bearer":
+		token := authField(auth.Bearer, "token")
+		if token == "" {
+			token = "{token}"
+		}
+		setHeader("Authorization", "Bearer "+token)
+	case "oauth2":
+		token := authField(auth.OAuth2, "accessToken")
+		if token == "" {
+			token = "{token}"
+		}
+		setHeader("Authorization", "Bearer "+token)
+	case "basic":
+		username, password := authField(auth.Basic, "username"), authField(auth.Basic, "password")
+		if placeholderPattern.MatchString(username + password) {
+			// 含变量的凭据无法在导入时编码
+			setHeader("Authorization", "Basic {basic_auth}")
+			p.result.Warnings = append(p.result.Warnings,
+				fmt.Sprintf("%s: basic 认证包含变量，请通过 {basic_auth} 传入 base64(用户名:密码)，或改用 auth_profiles", key))
+			return
+		}
+		setHeader("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(username+":"+password)))
+	case "apikey":
+		name, value := authField(auth.APIKey, "key"), authField(auth.APIKey, "value")
+		if name == "" {
+			name = "X-API-Key"
+		}
+		if authField(auth.APIKey, "in") == "query" {
+			if req.Query == nil {
+				req.Query = make(map[string]interface{})
+			}
+			req.Query[name] = value
+			return
+		}
+		setHeader(name, value)
+	default:
+		p.result.Warnings = append(p.result.Warnings, fmt.Sprintf("%s: 不支持的认证类型 %s，已忽略", key, auth.Type))
+	}
+}
+
+// convertPostmanVariables 将 {{var}} 转换为 {var}
+func convertPostmanVariables(text string) string {
+	return postmanVariablePattern.ReplaceAllString(text, "{$1}")
+}
+
+// parseJSONObjectBody 将 JSON 对象文本解析为 data
+// 未加引号的占位符（如 "id": {id}）会加上引号后再解析，替换时按参数的 JSON 类型还原
+func parseJSONObjectBody(raw string) (map[string]interface{}, bool) {
+	var data map[string]interface{}
+	if err := json.Unmarshal([]byte(raw), &data); err == nil {
+		return data, data != nil
+	}
+	quoted := regexp.MustCompile(`([:\[,]\s*)(\{[A-Za-z_][A-Za-z0-9_.\-]*\})`).ReplaceAllString(raw, `$1"$2"`)
+	if err := json.Unmarshal([]byte(quoted), &data); err == nil {
+		return data, data != nil
+	}
+	return nil, false
+}
+
+// addFormValue 添加表单值，同名字段转换为数组
+func addFormValue(values map[string]interface{}, name, value string) {
+	switch existing := values[name].(type) {
+	case nil:
+		values[name] = value
+	case []interface{}:
+		values[name] = append(existing, value)
+	default:
+		values[name] = []interface{}{existing, value}
+	}
+}
+
+func authField(fields []postmanField, key string) string {
+	for _, field := range fields {
+		if field.Key == key {
+			return convertPostmanVariables(fieldString(field.Value))
+		}
+	}
+	return ""
+}
+
+func fieldString(value interface{}) string {
+	if value == nil {
+		return ""
+	}
+	return stringifyValue(value)
+}
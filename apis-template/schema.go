@@ -0,0 +1,471 @@
+package apisTemplate
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxSchemaDepth $ref 递归的最大深度，防止自引用 schema 导致死循环
+const maxSchemaDepth = 64
+
+// SchemaViolation 一处不符合 JSON Schema 的位置
+type SchemaViolation struct {
+	Pointer string // 违规值的 JSON Pointer，根节点为 ""
+	Message string // 违规原因
+}
+
+func (v SchemaViolation) String() string {
+	pointer := v.Pointer
+	if pointer == "" {
+		pointer = "/"
+	}
+	return fmt.Sprintf("%s: %s", pointer, v.Message)
+}
+
+// LoadSchemaFile 将 expect.schema 中的文件引用替换为文件内容
+// schema 为字符串时视为文件路径，相对路径基于 baseDir（测试文件所在目录）
+func LoadSchemaFile(expect *ExpectConfig, baseDir string) error {
+	if expect == nil || len(expect.Schema) == 0 {
+		return nil
+	}
+	var path string
+	if err := json.Unmarshal(expect.Schema, &path); err != nil {
+		// 非字符串即为内联 schema
+		return nil
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(baseDir, path)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("读取 schema 文件失败: %v", err)
+	}
+	if !json.Valid(content) {
+		return fmt.Errorf("schema 文件 '%s' 不是有效的 JSON", path)
+	}
+	expect.Schema = content
+	return nil
+}
+
+// ValidateSchema 使用 JSON Schema（draft-07）校验解析后的 JSON，返回所有违规位置
+// 仅支持文档内部的 $ref（如 "#/definitions/user"），不会访问网络
+func ValidateSchema(schema json.RawMessage, document interface{}) ([]SchemaViolation, error) {
+	var root interface{}
+	if err := json.Unmarshal(schema, &root); err != nil {
+		return nil, fmt.Errorf("解析 schema 失败: %v", err)
+	}
+	if _, ok := root.(string); ok {
+		return nil, fmt.Errorf("schema 文件未加载: %s", string(schema))
+	}
+
+	v := &schemaValidator{root: root}
+	v.validate(root, document, "", 0)
+	if v.err != nil {
+		return nil, v.err
+	}
+	return v.violations, nil
+}
+
+type schemaValidator struct {
+	root       interface{}
+	violations []SchemaViolation
+	err        error // schema 本身的错误（如无法解析的 $ref）
+}
+
+func (v *schemaValidator) report(pointer, format string, args ...interface{}) {
+	v.violations = append(v.violations, SchemaViolation{Pointer: pointer, Message: fmt.Sprintf(format, args...)})
+}
+
+// valid 在独立的校验器中校验，用于 anyOf / oneOf / not 等只关心是否通过的场景
+func (v *schemaValidator) valid(schema, value interface{}, pointer string, depth int) bool {
+	sub := &schemaValidator{root: v.root}
+	sub.validate(schema, value, pointer, depth)
+	if sub.err != nil && v.err == nil {
+		v.err = sub.err
+	}
+	return len(sub.violations) == 0
+}
+
+func (v *schemaValidator) validate(schema, value interface{}, pointer string, depth int) {
+	if v.err != nil {
+		return
+	}
+	if depth > maxSchemaDepth {
+		v.err = fmt.Errorf("schema 嵌套过深（$ref 可能存在循环引用）")
+		return
+	}
+
+	switch s := schema.(type) {
+	case bool:
+		if !s {
+			v.report(pointer, "schema 为 false，不允许任何值")
+		}
+		return
+	case map[string]interface{}:
+		// draft-07 中 $ref 存在时忽略同级的其它关键字
+		if ref, ok := s["$ref"].(string); ok {
+			target, err := v.resolveRef(ref)
+			if err != nil {
+				v.err = err
+				return
+			}
+			v.validate(target, value, pointer, depth+1)
+			return
+		}
+		v.validateObjectSchema(s, value, pointer, depth)
+	default:
+		v.err = fmt.Errorf("schema 格式错误: 期望对象或布尔值")
+	}
+}
+
+func (v *schemaValidator) validateObjectSchema(s map[string]interface{}, value interface{}, pointer string, depth int) {
+	// 通用关键字
+	if t, ok := s["type"]; ok && !matchesType(t, value) {
+		v.report(pointer, "类型不匹配: 期望 %s, 实际 %s", formatJSONValue(t), jsonType(value))
+		// 类型不对时其它关键字的结果没有意义
+		return
+	}
+	if enum, ok := s["enum"].([]interface{}); ok {
+		found := false
+		for _, item := range enum {
+			if jsonEqual(item, value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			v.report(pointer, "值 %s 不在枚举 %s 中", formatJSONValue(value), formatJSONValue(enum))
+		}
+	}
+	if c, ok := s["const"]; ok && !jsonEqual(c, value) {
+		v.report(pointer, "值必须为 %s, 实际 %s", formatJSONValue(c), formatJSONValue(value))
+	}
+
+	// 组合关键字
+	if allOf, ok := s["allOf"].([]interface{}); ok {
+		for _, sub := range allOf {
+			v.validate(sub, value, pointer, depth+1)
+		}
+	}
+	if anyOf, ok := s["anyOf"].([]interface{}); ok {
+		matched := false
+		for _, sub := range anyOf {
+			if v.valid(sub, value, pointer, depth+1) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			v.report(pointer, "不满足 anyOf 中的任何一个 schema")
+		}
+	}
+	if oneOf, ok := s["oneOf"].([]interface{}); ok {
+		count := 0
+		for _, sub := range oneOf {
+			if v.valid(sub, value, pointer, depth+1) {
+				count++
+			}
+		}
+		if count != 1 {
+			v.report(pointer, "必须恰好满足 oneOf 中的一个 schema, 实际满足 %d 个", count)
+		}
+	}
+	if not, ok := s["not"]; ok && v.valid(not, value, pointer, depth+1) {
+		v.report(pointer, "不能满足 not 中的 schema")
+	}
+	if ifSchema, ok := s["if"]; ok {
+		if v.valid(ifSchema, value, pointer, depth+1) {
+			if then, ok := s["then"]; ok {
+				v.validate(then, value, pointer, depth+1)
+			}
+		} else if elseSchema, ok := s["else"]; ok {
+			v.validate(elseSchema, value, pointer, depth+1)
+		}
+	}
+
+	switch val := value.(type) {
+	case string:
+		v.validateString(s, val, pointer)
+	case float64:
+		v.validateNumber(s, val, pointer)
+	case []interface{}:
+		v.validateArray(s, val, pointer, depth)
+	case map[string]interface{}:
+		v.validateObject(s, val, pointer, depth)
+	}
+}
+
+func (v *schemaValidator) validateString(s map[string]interface{}, val, pointer string) {
+	length := utf8.RuneCountInString(val)
+	if min, ok := schemaNumber(s, "minLength"); ok && float64(length) < min {
+		v.report(pointer, "字符串长度 %d 小于 minLength %v", length, min)
+	}
+	if max, ok := schemaNumber(s, "maxLength"); ok && float64(length) > max {
+		v.report(pointer, "字符串长度 %d 大于 maxLength %v", length, max)
+	}
+	if pattern, ok := s["pattern"].(string); ok {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			v.err = fmt.Errorf("schema 中的 pattern '%s' 无效: %v", pattern, err)
+			return
+		}
+		if !re.MatchString(val) {
+			v.report(pointer, "字符串 %s 不匹配 pattern '%s'", formatJSONValue(val), pattern)
+		}
+	}
+}
+
+func (v *schemaValidator) validateNumber(s map[string]interface{}, val float64, pointer string) {
+	if min, ok := schemaNumber(s, "minimum"); ok && val < min {
+		v.report(pointer, "数值 %v 小于 minimum %v", val, min)
+	}
+	if max, ok := schemaNumber(s, "maximum"); ok && val > max {
+		v.report(pointer, "数值 %v 大于 maximum %v", val, max)
+	}
+	if min, ok := schemaNumber(s, "exclusiveMinimum"); ok && val <= min {
+		v.report(pointer, "数值 %v 必须大于 exclusiveMinimum %v", val, min)
+	}
+	if max, ok := schemaNumber(s, "exclusiveMaximum"); ok && val >= max {
+		v.report(pointer, "数值 %v 必须小于 exclusiveMaximum %v", val, max)
+	}
+	if factor, ok := schemaNumber(s, "multipleOf"); ok && factor > 0 {
+		quotient := val / factor
+		if math.Abs(quotient-math.Round(quotient)) > 1e-9 {
+			v.report(pointer, "数值 %v 不是 %v 的倍数", val, factor)
+		}
+	}
+}
+
+func (v *schemaValidator) validateArray(s map[string]interface{}, val []interface{}, pointer string, depth int) {
+	if min, ok := schemaNumber(s, "minItems"); ok && float64(len(val)) < min {
+		v.report(pointer, "数组长度 %d 小于 minItems %v", len(val), min)
+	}
+	if max, ok := schemaNumber(s, "maxItems"); ok && float64(len(val)) > max {
+		v.report(pointer, "数组长度 %d 大于 maxItems %v", len(val), max)
+	}
+	if unique, _ := s["uniqueItems"].(bool); unique {
+		for i := 0; i < len(val); i++ {
+			for j := i + 1; j < len(val); j++ {
+				if jsonEqual(val[i], val[j]) {
+					v.report(pointer, "数组元素 %d 与 %d 重复", i, j)
+				}
+			}
+		}
+	}
+
+	switch items := s["items"].(type) {
+	case []interface{}:
+		// 元组形式：按位置校验，多余的元素由 additionalItems 校验
+		for i, item := range val {
+			itemPointer := pointer + "/" + strconv.Itoa(i)
+			if i < len(items) {
+				v.validate(items[i], item, itemPointer, depth+1)
+			} else if additional, ok := s["additionalItems"]; ok {
+				v.validate(additional, item, itemPointer, depth+1)
+			}
+		}
+	case nil:
+	default:
+		for i, item := range val {
+			v.validate(items, item, pointer+"/"+strconv.Itoa(i), depth+1)
+		}
+	}
+
+	if contains, ok := s["contains"]; ok {
+		matched := false
+		for i, item := range val {
+			if v.valid(contains, item, pointer+"/"+strconv.Itoa(i), depth+1) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			v.report(pointer, "数组中没有满足 contains 的元素")
+		}
+	}
+}
+
+func (v *schemaValidator) validateObject(s map[string]interface{}, val map[string]interface{}, pointer string, depth int) {
+	if min, ok := schemaNumber(s, "minProperties"); ok && float64(len(val)) < min {
+		v.report(pointer, "字段数 %d 小于 minProperties %v", len(val), min)
+	}
+	if max, ok := schemaNumber(s, "maxProperties"); ok && float64(len(val)) > max {
+		v.report(pointer, "字段数 %d 大于 maxProperties %v", len(val), max)
+	}
+	if required, ok := s["required"].([]interface{}); ok {
+		for _, name := range required {
+			key, _ := name.(string)
+			if _, exists := val[key]; !exists {
+				v.report(pointer, "缺少必需字段 '%s'", key)
+			}
+		}
+	}
+
+	properties, _ := s["properties"].(map[string]interface{})
+	patternProperties, _ := s["patternProperties"].(map[string]interface{})
+	additional, hasAdditional := s["additionalProperties"]
+
+	for _, key := range sortedKeys(val) {
+		item := val[key]
+		keyPointer := pointer + "/" + escapePointer(key)
+		matched := false
+		if sub, ok := properties[key]; ok {
+			matched = true
+			v.validate(sub, item, keyPointer, depth+1)
+		}
+		for _, pattern := range sortedKeys(patternProperties) {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				v.err = fmt.Errorf("schema 中的 patternProperties '%s' 无效: %v", pattern, err)
+				return
+			}
+			if re.MatchString(key) {
+				matched = true
+				v.validate(patternProperties[pattern], item, keyPointer, depth+1)
+			}
+		}
+		if !matched && hasAdditional {
+			if allowed, ok := additional.(bool); ok && !allowed {
+				v.report(keyPointer, "不允许的额外字段 '%s'", key)
+			} else if !ok {
+				v.validate(additional, item, keyPointer, depth+1)
+			}
+		}
+		if names, ok := s["propertyNames"]; ok && !v.valid(names, key, keyPointer, depth+1) {
+			v.report(keyPointer, "字段名 '%s' 不满足 propertyNames", key)
+		}
+	}
+
+	if dependencies, ok := s["dependencies"].(map[string]interface{}); ok {
+		for _, key := range sortedKeys(dependencies) {
+			if _, exists := val[key]; !exists {
+				continue
+			}
+			switch dep := dependencies[key].(type) {
+			case []interface{}:
+				for _, name := range dep {
+					required, _ := name.(string)
+					if _, exists := val[required]; !exists {
+						v.report(pointer, "存在字段 '%s' 时必须同时存在 '%s'", key, required)
+					}
+				}
+			default:
+				v.validate(dep, val, pointer, depth+1)
+			}
+		}
+	}
+}
+
+// resolveRef 解析文档内部的 $ref
+func (v *schemaValidator) resolveRef(ref string) (interface{}, error) {
+	if !strings.HasPrefix(ref, "#") {
+		return nil, fmt.Errorf("不支持外部 $ref: %s", ref)
+	}
+	fragment, err := url.PathUnescape(ref[1:])
+	if err != nil {
+		return nil, fmt.Errorf("$ref '%s' 格式错误: %v", ref, err)
+	}
+	if fragment == "" {
+		return v.root, nil
+	}
+	if !strings.HasPrefix(fragment, "/") {
+		return nil, fmt.Errorf("不支持的 $ref: %s", ref)
+	}
+
+	current := v.root
+	for _, token := range strings.Split(fragment[1:], "/") {
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		switch node := current.(type) {
+		case map[string]interface{}:
+			next, ok := node[token]
+			if !ok {
+				return nil, fmt.Errorf("$ref '%s' 指向的定义不存在", ref)
+			}
+			current = next
+		case []interface{}:
+			index, err := strconv.Atoi(token)
+			if err != nil || index < 0 || index >= len(node) {
+				return nil, fmt.Errorf("$ref '%s' 指向的定义不存在", ref)
+			}
+			current = node[index]
+		default:
+			return nil, fmt.Errorf("$ref '%s' 指向的定义不存在", ref)
+		}
+	}
+	return current, nil
+}
+
+// matchesType 检查值是否符合 type 关键字（字符串或字符串数组）
+func matchesType(t interface{}, value interface{}) bool {
+	switch typ := t.(type) {
+	case string:
+		actual := jsonType(value)
+		if typ == "number" && actual == "integer" {
+			return true
+		}
+		return typ == actual
+	case []interface{}:
+		for _, item := range typ {
+			if matchesType(item, value) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// jsonType 返回 JSON 值的 schema 类型名，整数值返回 "integer"
+func jsonType(value interface{}) string {
+	switch val := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case float64:
+		if val == math.Trunc(val) && !math.IsInf(val, 0) {
+			return "integer"
+		}
+		return "number"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+// jsonEqual 按 JSON 语义比较两个值
+func jsonEqual(a, b interface{}) bool {
+	return reflect.DeepEqual(normalizeJSON(a), normalizeJSON(b))
+}
+
+func schemaNumber(s map[string]interface{}, key string) (float64, bool) {
+	n, ok := s[key].(float64)
+	return n, ok
+}
+
+// escapePointer 按 RFC 6901 转义 JSON Pointer 中的字段名
+func escapePointer(key string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(key, "~", "~0"), "/", "~1")
+}
+
+// formatViolations 将违规列表格式化为一条错误信息
+func formatViolations(violations []SchemaViolation) string {
+	lines := make([]string, len(violations))
+	for i, violation := range violations {
+		lines[i] = violation.String()
+	}
+	return strings.Join(lines, "; ")
+}
@@ -1,7 +1,9 @@
 package browseTemplate
 
 import (
+	apisTemplate "autotest/apis-template"
 	"autotest/browse-template/utils"
+	"encoding/json"
 	"fmt"
 	"os"
 
@@ -10,12 +12,62 @@ import (
 
 // Config 配置结构
 type Config struct {
-	Browser           string `yaml:"browser"`             // chromium, firefox, webkit
-	Headless          bool   `yaml:"headless"`            // 是否无头模式
-	Timeout           int    `yaml:"timeout"`             // 超时时间（毫秒）
-	RetryCaptcha      int    `yaml:"retry_captcha"`       // 验证码重试次数
-	IgnoreHTTPSErrors bool   `yaml:"ignore_https_errors"` // 是否忽略 HTTPS 证书错误（仅测试环境建议开启）
-	KeepBrowserOpen   bool   `yaml:"keep_browser_open"`   // 测试结束后是否保留浏览器（仅调试时建议开启）
+	Browser           string         `yaml:"browser"`             // chromium, firefox, webkit
+	Headless          bool           `yaml:"headless"`            // 是否无头模式
+	Timeout           int            `yaml:"timeout"`             // 超时时间（毫秒）
+	RetryCaptcha      int            `yaml:"retry_captcha"`       // 验证码重试次数
+	IgnoreHTTPSErrors bool           `yaml:"ignore_https_errors"` // 是否忽略 HTTPS 证书错误（仅测试环境建议开启）
+	KeepBrowserOpen   bool           `yaml:"keep_browser_open"`   // 测试结束后是否保留浏览器（仅调试时建议开启）
+	FailFast          bool           `yaml:"fail_fast"`           // 遇到第一个失败用例后停止执行（默认执行全部用例）
+	AutoWait          AutoWaitConfig `yaml:"auto_wait"`           // 每个步骤执行后的自动等待
+	Retries           int            `yaml:"retries"`             // 用例失败后的默认重试次数（用例可通过 retries 覆盖）
+	StepRetry         RetryConfig    `yaml:"step_retry"`          // 步骤失败后的默认重试策略（步骤可通过 retry 覆盖）
+	// API 用例使用的 HTTP 客户端（超时、证书、代理、重定向）
+	APIClient apisTemplate.ClientConfig `yaml:"api_client"`
+	// API 模板通过 "auth": "名称" 引用的认证配置
+	AuthProfiles apisTemplate.AuthProfiles `yaml:"auth_profiles"`
+	// HAR 录制与回放：先连接真实设备录制，之后在没有后端的环境中回放
+	RecordHAR   string `yaml:"record_har"`    // 录制目录，每个用例录制一个 HAR 文件
+	ReplayHAR   string `yaml:"replay_har"`    // 回放目录（按用例名查找 HAR 文件）或单个 .har / .zip 文件
+	HARNotFound string `yaml:"har_not_found"` // 回放时请求未命中的处理: "abort"（默认）, "fallthrough"
+	// 页面错误信号（控制台错误、未捕获异常、失败请求、错误响应）导致用例失败的条件
+	BrowserErrors BrowserErrorsConfig `yaml:"browser_errors"`
+	// Playwright trace 录制: "off"（默认）, "on", "retain-on-failure"
+	Trace string `yaml:"trace"`
+}
+
+// APIClientConfig 返回 API 客户端配置，未设置 insecure_skip_verify 时沿用 ignore_https_errors
+func (c *Config) APIClientConfig() apisTemplate.ClientConfig {
+	clientConfig := c.APIClient
+	if clientConfig.InsecureSkipVerify == nil {
+		insecure := c.IgnoreHTTPSErrors
+		clientConfig.InsecureSkipVerify = &insecure
+	}
+	return clientConfig
+}
+
+// RetryConfig 重试策略
+type RetryConfig struct {
+	Times      int `json:"times" yaml:"times"`             // 失败后的重试次数（不含首次执行）
+	IntervalMs int `json:"interval_ms" yaml:"interval_ms"` // 重试间隔（毫秒）
+}
+
+// BrowserErrorsConfig 页面错误信号配置
+// 信号始终记录到用例结果中，以下开关决定是否导致用例失败
+type BrowserErrorsConfig struct {
+	FailOnPageError     bool     `yaml:"fail_on_page_error"`     // 未捕获的 JS 异常
+	FailOnServerError   bool     `yaml:"fail_on_server_error"`   // 5xx 响应
+	FailOnConsoleError  bool     `yaml:"fail_on_console_error"`  // console.error 输出
+	FailOnRequestFailed bool     `yaml:"fail_on_request_failed"` // 网络层失败的请求（连接失败、超时等，不含被取消的请求）
+	Allow               []string `yaml:"allow"`                  // 允许的 URL 或消息，匹配规则同 wait_for_response，匹配时不导致失败
+}
+
+// AutoWaitConfig 步骤执行后的自动等待配置
+// 取代固定时长的 sleep：等待网络空闲、加载遮罩消失后再执行下一步
+type AutoWaitConfig struct {
+	Disabled     bool     `yaml:"disabled"`      // 关闭自动等待
+	NetworkIdle  int      `yaml:"network_idle"`  // 等待网络空闲的最长时间（毫秒），超时不视为失败
+	LoadingMasks []string `yaml:"loading_masks"` // 需要等待消失的加载遮罩选择器
 }
 
 // LoadConfig 从文件加载配置
@@ -47,9 +99,21 @@ func LoadConfig(configPath string) (*Config, error) {
 	if config.RetryCaptcha == 0 {
 		config.RetryCaptcha = defaultConfig.RetryCaptcha
 	}
+	if config.AutoWait.NetworkIdle == 0 {
+		config.AutoWait.NetworkIdle = defaultConfig.AutoWait.NetworkIdle
+	}
+	if config.AutoWait.LoadingMasks == nil {
+		config.AutoWait.LoadingMasks = defaultConfig.AutoWait.LoadingMasks
+	}
 	// 默认不忽略 HTTPS 错误，除非配置中显式开启
 	// 这里不强制设置，保持配置文件的布尔值即可
 
+	if err := config.validateHAR(); err != nil {
+		return nil, err
+	}
+	if err := config.validateTrace(); err != nil {
+		return nil, err
+	}
 	return &config, nil
 }
 
@@ -62,6 +126,11 @@ func DefaultConfig() *Config {
 		RetryCaptcha:      3,
 		IgnoreHTTPSErrors: false,
 		KeepBrowserOpen:   false,
+		FailFast:          false,
+		AutoWait: AutoWaitConfig{
+			NetworkIdle:  3000,
+			LoadingMasks: utils.DefaultLoadingMasks,
+		},
 	}
 }
 
@@ -75,7 +144,7 @@ type ExpectConfig struct {
 
 // TestStep 测试步骤
 type TestStep struct {
-	Action    string                 `json:"action"`              // "goto", "input", "click", "assert", "menu_click", "captcha_input", "select_option", "select_options", "checkbox_toggle", "checkbox_set", "checkboxes_set", "radio_select", "radios_select", "table_edit", "table_delete", "table_assert", "search"
+	Action    string                 `json:"action"`              // "goto", "input", "click", "assert", "menu_click", "captcha_input", "select_option", "select_options", "checkbox_toggle", "checkbox_set", "checkboxes_set", "radio_select", "radios_select", "table_edit", "table_delete", "table_assert", "search", "wait_for", "wait_for_url", "wait_for_response", "wait_ms", "wait_for_api", "route_mock", "route_clear", "switch_page", "close_page"
 	URL       string                 `json:"url,omitempty"`       // goto的URL
 	Selector  *utils.SelectorConfig  `json:"selector,omitempty"`  // 元素选择器（单个）
 	Selectors []utils.SelectorConfig `json:"selectors,omitempty"` // 元素选择器（多个，用于批量操作）
@@ -87,6 +156,50 @@ type TestStep struct {
 	Checked   *bool                  `json:"checked,omitempty"`   // checkbox_set时使用，true表示选中，false表示取消选中
 	Table     *TableConfig           `json:"table,omitempty"`     // 表格配置
 	Search    *SearchConfig          `json:"search,omitempty"`    // 查询配置
+	// 显式等待字段
+	State   string `json:"state,omitempty"`   // wait_for 等待的元素状态: "attached", "detached", "visible"（默认）, "hidden"
+	Status  int    `json:"status,omitempty"`  // wait_for_response 期望的响应状态码，0 表示不限
+	Ms      int    `json:"ms,omitempty"`      // wait_ms 等待的毫秒数
+	Timeout int    `json:"timeout,omitempty"` // 等待超时（毫秒），默认使用配置文件中的 timeout
+	// 失败重试策略，未设置时使用配置文件中的 step_retry
+	Retry *RetryConfig `json:"retry,omitempty"`
+	// wait_for_api 轮询的接口，需配置 poll.until
+	API *apisTemplate.TestCaseConfig `json:"api,omitempty"`
+	// route_mock 拦截 url 匹配的请求后的处理方式
+	Mock *RouteMockConfig `json:"mock,omitempty"`
+	// switch_page 切换到的页面，close_page 关闭的页面（未设置时关闭当前页面）
+	Page *PageConfig `json:"page,omitempty"`
+}
+
+// PageConfig 页面（标签页、弹出窗口）定位配置，index、title、url、popup 只能配置一个
+type PageConfig struct {
+	Index int    `json:"index,omitempty"` // 按打开顺序的序号（从 1 开始）
+	Title string `json:"title,omitempty"` // 页面标题，匹配规则同 wait_for_url
+	URL   string `json:"url,omitempty"`   // 页面地址，匹配规则同 wait_for_url
+	Popup bool   `json:"popup,omitempty"` // 用例中下一个新打开的页面，尚未打开时等待
+}
+
+// RouteMockConfig 请求拦截配置，按以下优先级处理匹配的请求：
+// abort 中止请求；patch 请求真实接口后修改响应；status / headers / body / body_file 直接返回模拟响应；
+// 只配置 delay_ms 时延迟后照常发送请求
+type RouteMockConfig struct {
+	Method   string            `json:"method,omitempty"`    // 只拦截指定的请求方法，默认全部
+	Times    int               `json:"times,omitempty"`     // 拦截次数，0 表示不限
+	Status   int               `json:"status,omitempty"`    // 响应状态码，默认 200
+	Headers  map[string]string `json:"headers,omitempty"`   // 响应头
+	Body     json.RawMessage   `json:"body,omitempty"`      // 响应体：字符串原样返回，对象或数组按 JSON 返回
+	BodyFile string            `json:"body_file,omitempty"` // 从文件读取响应体，Content-Type 按扩展名推断
+	DelayMs  int               `json:"delay_ms,omitempty"`  // 处理前延迟的毫秒数，用于模拟慢接口
+	Abort    string            `json:"abort,omitempty"`     // 中止请求的错误码，如 "failed"、"timedout"、"connectionrefused"
+	Patch    []JSONPatchOp     `json:"patch,omitempty"`     // 对真实响应的 JSON 执行的 JSON Patch (RFC 6902)
+}
+
+// JSONPatchOp JSON Patch 操作
+type JSONPatchOp struct {
+	Op    string          `json:"op"`             // "add", "remove", "replace", "move", "copy", "test"
+	Path  string          `json:"path"`           // JSON Pointer，如 "/data/items/0/status"
+	From  string          `json:"from,omitempty"` // move / copy 的来源
+	Value json.RawMessage `json:"value,omitempty"`
 }
 
 // TableConfig 表格配置
@@ -0,0 +1,101 @@
+package browseTemplate
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// HAR 回放时请求未命中的处理策略（配置文件中的 har_not_found）
+const (
+	HARNotFoundAbort       = "abort"       // 中止请求（默认），保证测试不依赖真实后端
+	HARNotFoundFallthrough = "fallthrough" // 照常发送到网络
+)
+
+// SharedHARName 共享页面模式下整个运行过程录制 / 回放的 HAR 名称
+const SharedHARName = "session"
+
+// fileNamePattern 文件名中不允许出现的字符
+var fileNamePattern = regexp.MustCompile(`[\\/:*?"<>|\s]+`)
+
+// caseFileName 将用例名转换为可用作文件名的字符串，转换后为空时返回空字符串
+func caseFileName(caseName string) string {
+	return strings.Trim(fileNamePattern.ReplaceAllString(caseName, "_"), "_.")
+}
+
+// HARPath 返回用例对应的 HAR 文件路径
+// path 以 .har / .zip 结尾时视为文件，所有用例共用；否则视为目录，文件名取自用例名
+func HARPath(path, caseName string) string {
+	if isHARFile(path) {
+		return path
+	}
+	name := caseFileName(caseName)
+	if name == "" {
+		name = SharedHARName
+	}
+	return filepath.Join(path, name+".har")
+}
+
+func isHARFile(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	return ext == ".har" || ext == ".zip"
+}
+
+// RecordHAR 将上下文中的请求与响应录制到 HAR 文件，上下文关闭时写入磁盘
+// .har 文件内嵌响应内容；.zip 文件将响应内容作为单独的条目保存
+func RecordHAR(ctx playwright.BrowserContext, path string) error {
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("创建 HAR 目录失败: %v", err)
+		}
+	}
+	// RouteFromHAR 按指针比较内容策略，必须使用 playwright 包中的枚举值
+	content := playwright.RouteFromHarUpdateContentPolicyEmbed
+	if strings.EqualFold(filepath.Ext(path), ".zip") {
+		content = playwright.RouteFromHarUpdateContentPolicyAttach
+	}
+	err := ctx.RouteFromHAR(path, playwright.BrowserContextRouteFromHAROptions{
+		Update:        playwright.Bool(true),
+		UpdateContent: content,
+		UpdateMode:    playwright.HarModeFull,
+	})
+	if err != nil {
+		return fmt.Errorf("开始录制 HAR 失败: %v", err)
+	}
+	return nil
+}
+
+// ReplayHAR 使用 HAR 文件中录制的响应返回上下文中的请求，notFound 为未命中时的处理策略
+func ReplayHAR(ctx playwright.BrowserContext, path, notFound string) error {
+	if _, err := os.Stat(path); err != nil {
+		return fmt.Errorf("HAR 文件不存在: %s", path)
+	}
+	policy := playwright.HarNotFoundAbort
+	if notFound == HARNotFoundFallthrough {
+		policy = playwright.HarNotFoundFallback
+	}
+	if err := ctx.RouteFromHAR(path, playwright.BrowserContextRouteFromHAROptions{NotFound: policy}); err != nil {
+		return fmt.Errorf("加载 HAR 失败: %v", err)
+	}
+	return nil
+}
+
+// validateHAR 检查 HAR 相关配置
+func (c *Config) validateHAR() error {
+	if c.RecordHAR != "" && c.ReplayHAR != "" {
+		return fmt.Errorf("record_har 与 replay_har 不能同时配置")
+	}
+	if c.RecordHAR != "" && isHARFile(c.RecordHAR) {
+		return fmt.Errorf("record_har 应为目录（每个用例录制一个 HAR 文件）: %s", c.RecordHAR)
+	}
+	switch c.HARNotFound {
+	case "", HARNotFoundAbort, HARNotFoundFallthrough:
+		return nil
+	default:
+		return fmt.Errorf("har_not_found 无效: %s（支持: abort, fallthrough）", c.HARNotFound)
+	}
+}
@@ -1,7 +1,10 @@
 package browseTemplate
 
 import (
+	"fmt"
 	"log"
+	"os"
+	"path/filepath"
 	"time"
 
 	"github.com/playwright-community/playwright-go"
@@ -9,17 +12,44 @@ import (
 
 var browser playwright.Browser
 var context playwright.BrowserContext
+var browserConfig *Config
 
 // Start 启动浏览器（使用默认配置）
 func Start() playwright.Page {
 	return StartWithConfig(nil)
 }
 
-// StartWithConfig 使用指定配置启动浏览器
+// StartWithConfig 使用指定配置启动浏览器，并创建一个全局共享的上下文和页面
 func StartWithConfig(cfg *Config) playwright.Page {
+	StartBrowser(cfg)
+
+	var err error
+	var page playwright.Page
+	context, page, err = NewContext("")
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+
+	// 共享上下文在整个运行过程中只有一个，HAR 也只录制 / 回放一个
+	switch {
+	case browserConfig.RecordHAR != "":
+		err = RecordHAR(context, HARPath(browserConfig.RecordHAR, SharedHARName))
+	case browserConfig.ReplayHAR != "":
+		err = ReplayHAR(context, HARPath(browserConfig.ReplayHAR, SharedHARName), browserConfig.HARNotFound)
+	}
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+	return page
+}
+
+// StartBrowser 使用指定配置启动浏览器，不创建上下文
+// 之后通过 NewContext 为每个用例创建独立的上下文
+func StartBrowser(cfg *Config) playwright.Browser {
 	if cfg == nil {
 		cfg = DefaultConfig()
 	}
+	browserConfig = cfg
 
 	pw, err := playwright.Run()
 	if err != nil {
@@ -53,39 +83,79 @@ func StartWithConfig(cfg *Config) playwright.Page {
 	if err != nil {
 		log.Fatalf("启动浏览器失败 (%s): %v", cfg.Browser, err)
 	}
+	return browser
+}
+
+// NewContext 创建新的浏览器上下文及页面，上下文之间的 Cookie、localStorage 相互隔离
+// storageStatePath 非空时，从该文件加载之前保存的登录状态
+func NewContext(storageStatePath string) (playwright.BrowserContext, playwright.Page, error) {
+	if browser == nil {
+		return nil, nil, fmt.Errorf("浏览器未启动")
+	}
 
 	// 创建浏览器上下文
 	videoDir := "assets/videos"
-	context, err = browser.NewContext(playwright.BrowserNewContextOptions{
+	opts := playwright.BrowserNewContextOptions{
 		RecordVideo: &playwright.RecordVideo{
 			Dir: videoDir,
 		},
-	})
+	}
+	if storageStatePath != "" {
+		opts.StorageStatePath = playwright.String(storageStatePath)
+	}
+	ctx, err := browser.NewContext(opts)
 	if err != nil {
-		log.Fatalf("创建浏览器上下文失败: %v", err)
+		return nil, nil, fmt.Errorf("创建浏览器上下文失败: %v", err)
 	}
 
 	// 创建页面
-	page, err := context.NewPage()
+	page, err := ctx.NewPage()
 	if err != nil {
-		log.Fatalf("创建页面失败: %v", err)
+		ctx.Close()
+		return nil, nil, fmt.Errorf("创建页面失败: %v", err)
 	}
 
 	// 设置默认超时时间
-	page.SetDefaultTimeout(float64(cfg.Timeout))
+	page.SetDefaultTimeout(float64(browserConfig.Timeout))
 
-	return page
+	return ctx, page, nil
 }
 
-func TakeErrorScreenshot(page playwright.Page) {
+// SaveStorageState 将上下文的 Cookie 和 localStorage 保存到文件，供后续上下文复用
+func SaveStorageState(ctx playwright.BrowserContext, path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("创建存储状态目录失败: %v", err)
+	}
+	if _, err := ctx.StorageState(path); err != nil {
+		return fmt.Errorf("保存存储状态失败: %v", err)
+	}
+	return nil
+}
+
+// TakeErrorScreenshot 保存错误截图，返回截图文件路径（截图失败时返回空字符串）
+// 文件名包含用例名和时间，并行执行时不同用例同时失败也不会相互覆盖
+func TakeErrorScreenshot(page playwright.Page, caseName string) string {
 	timeStr := time.Now().Format("2006-01-02_15-04-05.000")
-	file := "assets/errors/error_" + timeStr + ".png"
-	page.Screenshot(playwright.PageScreenshotOptions{
+	prefix := "error_"
+	if name := caseFileName(caseName); name != "" {
+		prefix += name + "_"
+	}
+	file := "assets/errors/" + prefix + timeStr + ".png"
+	_, err := page.Screenshot(playwright.PageScreenshotOptions{
 		Path: playwright.String(file),
 	})
+	if err != nil {
+		log.Printf("错误截图失败: %v", err)
+		return ""
+	}
+	return file
 }
 
 func Stop() {
-	context.Close()
-	browser.Close()
+	if context != nil {
+		context.Close()
+	}
+	if browser != nil {
+		browser.Close()
+	}
 }
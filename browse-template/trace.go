@@ -0,0 +1,71 @@
+package browseTemplate
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// Trace 录制模式（配置文件中的 trace）
+const (
+	TraceOff             = "off"               // 不录制（默认）
+	TraceOn              = "on"                // 每个用例都保存 trace
+	TraceRetainOnFailure = "retain-on-failure" // 只保存失败用例的 trace
+)
+
+// DefaultTraceDir trace 文件的保存目录
+const DefaultTraceDir = "assets/traces"
+
+// TracePath 返回用例本次执行的 trace 文件路径，文件名包含时间，重试时不会相互覆盖
+func TracePath(caseName string) string {
+	name := caseFileName(caseName)
+	if name == "" {
+		name = "trace"
+	}
+	timeStr := time.Now().Format("2006-01-02_15-04-05.000")
+	return filepath.Join(DefaultTraceDir, name+"_"+timeStr+".zip")
+}
+
+// StartTrace 开始录制上下文的 trace（截图、DOM 快照、源码）
+func StartTrace(ctx playwright.BrowserContext, title string) error {
+	err := ctx.Tracing().Start(playwright.TracingStartOptions{
+		Title:       playwright.String(title),
+		Screenshots: playwright.Bool(true),
+		Snapshots:   playwright.Bool(true),
+		Sources:     playwright.Bool(true),
+	})
+	if err != nil {
+		return fmt.Errorf("开始录制 trace 失败: %v", err)
+	}
+	return nil
+}
+
+// StopTrace 停止录制 trace，path 非空时保存到该文件，为空时丢弃
+func StopTrace(ctx playwright.BrowserContext, path string) error {
+	if path == "" {
+		if err := ctx.Tracing().Stop(); err != nil {
+			return fmt.Errorf("停止录制 trace 失败: %v", err)
+		}
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("创建 trace 目录失败: %v", err)
+	}
+	if err := ctx.Tracing().Stop(path); err != nil {
+		return fmt.Errorf("保存 trace 失败: %v", err)
+	}
+	return nil
+}
+
+// validateTrace 检查 trace 配置
+func (c *Config) validateTrace() error {
+	switch c.Trace {
+	case "", TraceOff, TraceOn, TraceRetainOnFailure:
+		return nil
+	default:
+		return fmt.Errorf("trace 无效: %s（支持: off, on, retain-on-failure）", c.Trace)
+	}
+}
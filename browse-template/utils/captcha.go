@@ -59,9 +59,6 @@ func SolveAndInputCaptcha(page playwright.Page, captchaSelector, inputSelector S
 		return "", fmt.Errorf("输入验证码失败: %v", err)
 	}
 
-	// 等待一下，确保输入完成
-	time.Sleep(200 * time.Millisecond)
-
 	return captchaText, nil
 }
 
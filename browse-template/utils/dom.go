@@ -9,38 +9,43 @@ import (
 
 // SelectorConfig 选择器配置
 type SelectorConfig struct {
-	Type  string `json:"type"`            // "text", "xpath", "css", "id" 等
-	Value string `json:"value"`           // 选择器的值
-	Scope string `json:"scope,omitempty"` // 作用域: "", "dialog"（当前弹窗内查找）, 未来可扩展 "new_window" 等
+	Type  string       `json:"type"`            // "text", "xpath", "css", "id" 等
+	Value string       `json:"value"`           // 选择器的值
+	Scope string       `json:"scope,omitempty"` // 作用域: "", "dialog"（当前弹窗内查找）, "main"（主内容区域）, "frame"（iframe 内查找，需配置 frame）
+	Frame *FrameConfig `json:"frame,omitempty"` // 在 iframe 内查找，可与 dialog / main 组合使用；新标签页通过 switch_page 切换
 }
 
 // LocateElement 基于选择器配置定位元素
 // 支持多种定位方式，优先使用文本定位
 func LocateElement(page playwright.Page, selector SelectorConfig) (playwright.ElementHandle, error) {
+	root, err := scopeRoot(page, selector)
+	if err != nil {
+		return nil, err
+	}
 	switch selector.Type {
 	case "text":
-		return locateByText(page, selector.Value, selector.Scope)
+		return locateByText(root, selector.Value, selector.Scope)
 	case "field":
-		return locateField(page, selector.Value, selector.Scope)
+		return locateField(root, selector.Value, selector.Scope)
 	case "button":
-		return locateButton(page, selector.Value, selector.Scope)
+		return locateButton(root, selector.Value, selector.Scope)
 	case "xpath":
-		return page.Locator(selector.Value).First().ElementHandle()
+		return root(selector.Value).First().ElementHandle()
 	case "css":
-		return page.Locator(selector.Value).First().ElementHandle()
+		return root(selector.Value).First().ElementHandle()
 	case "id":
-		return page.Locator("#" + selector.Value).First().ElementHandle()
+		return root("#" + selector.Value).First().ElementHandle()
 	default:
 		// 默认尝试文本定位
-		return locateByText(page, selector.Value, selector.Scope)
+		return locateByText(root, selector.Value, selector.Scope)
 	}
 }
 
 // locateField 基于文本内容定位“字段输入控件”
 // 测试人员只需要写字段文字，例如: {type: "field", value: "策略名称"}
-func locateField(page playwright.Page, text string, scope string) (playwright.ElementHandle, error) {
+func locateField(base locatorRoot, text string, scope string) (playwright.ElementHandle, error) {
 	// 根据 scope 决定查找范围（与 locateByText 一致）
-	root := page.Locator("body")
+	root := base("body")
 	switch scope {
 	case "dialog":
 		dialogSelectors := []string{
@@ -55,7 +60,7 @@ func locateField(page playwright.Page, text string, scope string) (playwright.El
 
 		foundVisibleRoot := false
 		for _, ds := range dialogSelectors {
-			loc := page.Locator(ds)
+			loc := base(ds)
 			count, err := loc.Count()
 			if err == nil && count > 0 {
 				// 遍历所有找到的 dialog 节点，只选可见的那个
@@ -76,7 +81,7 @@ func locateField(page playwright.Page, text string, scope string) (playwright.El
 		// 如果指定了 scope 是 dialog 但没找到可见的 dialog，考虑回退到 body
 		if !foundVisibleRoot {
 			fmt.Println("[locateField] 警告: 未找到可见的 Dialog 容器，将尝试在全页面搜索...")
-			root = page.Locator("body")
+			root = base("body")
 		}
 	case "main":
 		mainSelectors := []string{
@@ -90,7 +95,7 @@ func locateField(page playwright.Page, text string, scope string) (playwright.El
 			"#app .content",
 		}
 		for _, ms := range mainSelectors {
-			loc := page.Locator(ms)
+			loc := base(ms)
 			count, err := loc.Count()
 			if err == nil && count > 0 {
 				root = loc
@@ -153,9 +158,9 @@ func locateField(page playwright.Page, text string, scope string) (playwright.El
 
 // locateButton 基于文本内容定位“可点击按钮”
 // 测试人员只需要写按钮文字，例如: {type: "button", value: "新建"}
-func locateButton(page playwright.Page, text string, scope string) (playwright.ElementHandle, error) {
+func locateButton(base locatorRoot, text string, scope string) (playwright.ElementHandle, error) {
 	// 根据 scope 决定查找范围（与 locateByText 一致）
-	root := page.Locator("body")
+	root := base("body")
 	switch scope {
 	case "dialog":
 		dialogSelectors := []string{
@@ -170,7 +175,7 @@ func locateButton(page playwright.Page, text string, scope string) (playwright.E
 
 		foundVisibleRoot := false
 		for _, ds := range dialogSelectors {
-			loc := page.Locator(ds)
+			loc := base(ds)
 			count, err := loc.Count()
 			if err == nil && count > 0 {
 				// 遍历所有找到的 dialog 节点，只选可见的那个
@@ -191,7 +196,7 @@ func locateButton(page playwright.Page, text string, scope string) (playwright.E
 		// 如果指定了 scope 是 dialog 但没找到可见的 dialog，考虑回退到 body
 		if !foundVisibleRoot {
 			fmt.Println("[locateButton] 警告: 未找到可见的 Dialog 容器，将尝试在全页面搜索...")
-			root = page.Locator("body")
+			root = base("body")
 		}
 	case "main":
 		mainSelectors := []string{
@@ -205,7 +210,7 @@ func locateButton(page playwright.Page, text string, scope string) (playwright.E
 			"#app .content",
 		}
 		for _, ms := range mainSelectors {
-			loc := page.Locator(ms)
+			loc := base(ms)
 			count, err := loc.Count()
 			if err == nil && count > 0 {
 				root = loc
@@ -313,8 +318,8 @@ func locateButton(page playwright.Page, text string, scope string) (playwright.E
 // 3. label标签关联
 // 4. aria-label属性
 // 5. title属性
-// scope: "", "dialog" 等，默认为整页查找
-func locateByText(page playwright.Page, text string, scope string) (playwright.ElementHandle, error) {
+// scope: "", "dialog" 等，默认在 base（整页或 iframe）中查找
+func locateByText(base locatorRoot, text string, scope string) (playwright.ElementHandle, error) {
 	// 为了让测试人员只写“可见文本”就能更稳定地定位到真正的输入框，
 	// 这里优先尝试 placeholder / label 关联到 input 的策略，
 	// 然后才回退到通用的 text= 文本匹配。
@@ -323,7 +328,7 @@ func locateByText(page playwright.Page, text string, scope string) (playwright.E
 	// 默认在 body 下查找；
 	// - scope == "dialog" : 在常见弹窗容器内查找
 	// - scope == "main"   : 在主内容区域查找（排除左侧菜单等）
-	root := page.Locator("body")
+	root := base("body")
 	switch scope {
 	case "dialog":
 		dialogSelectors := []string{
@@ -336,7 +341,7 @@ func locateByText(page playwright.Page, text string, scope string) (playwright.E
 			".dialog",
 		}
 		for _, ds := range dialogSelectors {
-			loc := page.Locator(ds)
+			loc := base(ds)
 			count, err := loc.Count()
 			if err == nil && count > 0 {
 				root = loc
@@ -355,7 +360,7 @@ func locateByText(page playwright.Page, text string, scope string) (playwright.E
 			"#app .content",
 		}
 		for _, ms := range mainSelectors {
-			loc := page.Locator(ms)
+			loc := base(ms)
 			count, err := loc.Count()
 			if err == nil && count > 0 {
 				root = loc
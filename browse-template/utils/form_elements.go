@@ -2,23 +2,28 @@ package utils
 
 import (
 	"fmt"
-	"time"
 
 	"github.com/playwright-community/playwright-go"
 )
 
 // SelectOption 选择下拉框选项（单选）
 // 支持通过文本、值选择
-func SelectOption(page playwright.Page, selectSelector SelectorConfig, optionValue string) error {
-	return SelectOptions(page, selectSelector, []string{optionValue})
+func SelectOption(page playwright.Page, selectSelector SelectorConfig, optionValue string, timeout float64) error {
+	return SelectOptions(page, selectSelector, []string{optionValue}, timeout)
 }
 
 // SelectOptions 选择下拉框选项（支持多选）
-// 支持通过文本、值选择多个选项
-func SelectOptions(page playwright.Page, selectSelector SelectorConfig, optionValues []string) error {
+// 支持通过文本、值选择多个选项；timeout 为等待自定义下拉框选项出现的超时时间（毫秒）
+func SelectOptions(page playwright.Page, selectSelector SelectorConfig, optionValues []string, timeout float64) error {
 	if len(optionValues) == 0 {
 		return fmt.Errorf("选项列表不能为空")
 	}
+	// 下拉框及其弹出的选项都在选择器所在的页面或 iframe 中查找
+	root, err := scopeRoot(page, selectSelector)
+	if err != nil {
+		return err
+	}
+
 	// 构建select元素的定位器
 	var selectLocator playwright.Locator
 
@@ -35,7 +40,7 @@ func SelectOptions(page playwright.Page, selectSelector SelectorConfig, optionVa
 
 		var found bool
 		for _, sel := range selectors {
-			loc := page.Locator(sel)
+			loc := root(sel)
 			count, err := loc.Count()
 			if err == nil && count > 0 {
 				selectLocator = loc
@@ -51,21 +56,21 @@ func SelectOptions(page playwright.Page, selectSelector SelectorConfig, optionVa
 				return fmt.Errorf("定位下拉框失败: %v", err)
 			}
 			// 获取元素的定位器（通过xpath）
-			selectLocator = page.Locator(fmt.Sprintf("//select[.//option[contains(text(), '%s')]]", selectSelector.Value))
+			selectLocator = root(fmt.Sprintf("//select[.//option[contains(text(), '%s')]]", selectSelector.Value))
 		}
 	case "xpath", "css", "id":
-		selectLocator = page.Locator(selectSelector.Value)
+		selectLocator = root(selectSelector.Value)
 	default:
 		_, err := LocateElement(page, selectSelector)
 		if err != nil {
 			return fmt.Errorf("定位下拉框失败: %v", err)
 		}
 		// 对于其他类型，直接使用选择器值
-		selectLocator = page.Locator(selectSelector.Value)
+		selectLocator = root(selectSelector.Value)
 	}
 
 	// 尝试通过label（文本）选择
-	_, err := selectLocator.SelectOption(playwright.SelectOptionValues{Labels: &optionValues})
+	_, err = selectLocator.SelectOption(playwright.SelectOptionValues{Labels: &optionValues})
 	if err != nil {
 		// 如果失败，尝试通过value选择
 		_, err = selectLocator.SelectOption(playwright.SelectOptionValues{Values: &optionValues})
@@ -89,7 +94,8 @@ func SelectOptions(page playwright.Page, selectSelector SelectorConfig, optionVa
 
 				err2 = selectElement.Click()
 				if err2 == nil {
-					time.Sleep(300 * time.Millisecond)
+					// 等待下拉选项弹出
+					waitForVisibleText(root, optionsToSelect[0], timeout)
 
 					// 逐个选择选项
 					for _, optionValue := range optionsToSelect {
@@ -102,7 +108,7 @@ func SelectOptions(page playwright.Page, selectSelector SelectorConfig, optionVa
 
 						var optionFound bool
 						for _, sel := range optionSelectors {
-							optionLocator := page.Locator(sel)
+							optionLocator := root(sel)
 							count, err3 := optionLocator.Count()
 							if err3 == nil && count > 0 {
 								optionElement, err4 := optionLocator.First().ElementHandle()
@@ -118,7 +124,6 @@ func SelectOptions(page playwright.Page, selectSelector SelectorConfig, optionVa
 									}
 									if err3 == nil {
 										optionFound = true
-										time.Sleep(100 * time.Millisecond)
 										break
 									}
 								}
@@ -130,7 +135,6 @@ func SelectOptions(page playwright.Page, selectSelector SelectorConfig, optionVa
 						}
 					}
 
-					time.Sleep(200 * time.Millisecond)
 					return nil
 				}
 			}
@@ -138,7 +142,6 @@ func SelectOptions(page playwright.Page, selectSelector SelectorConfig, optionVa
 		}
 	}
 
-	time.Sleep(200 * time.Millisecond)
 	return nil
 }
 
@@ -167,7 +170,6 @@ func ToggleCheckbox(page playwright.Page, checkboxSelector SelectorConfig) error
 		return fmt.Errorf("切换复选框状态失败: %v", err)
 	}
 
-	time.Sleep(200 * time.Millisecond)
 	return nil
 }
 
@@ -189,7 +191,6 @@ func SetCheckbox(page playwright.Page, checkboxSelector SelectorConfig, checked
 		return fmt.Errorf("设置复选框状态失败: %v", err)
 	}
 
-	time.Sleep(200 * time.Millisecond)
 	return nil
 }
 
@@ -215,11 +216,8 @@ func SelectRadios(page playwright.Page, radioSelectors []SelectorConfig) error {
 		if err != nil {
 			return fmt.Errorf("选择单选按钮失败: %v", err)
 		}
-
-		time.Sleep(100 * time.Millisecond)
 	}
 
-	time.Sleep(200 * time.Millisecond)
 	return nil
 }
 
@@ -0,0 +1,111 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// frameWaitTimeout 按 url 查找 iframe 时的最长等待时间，iframe 往往在页面加载后才创建
+const frameWaitTimeout = 5 * time.Second
+
+// FrameConfig iframe 定位配置，name、url、selector 只能配置一个
+type FrameConfig struct {
+	Name     string `json:"name,omitempty"`     // iframe 的 name 属性
+	URL      string `json:"url,omitempty"`      // iframe 的地址，匹配规则同 wait_for_url
+	Selector string `json:"selector,omitempty"` // iframe 元素的 CSS / XPath 选择器
+}
+
+// locatorRoot 元素查找的根：整个页面，或 iframe 内的文档
+type locatorRoot func(selector string) playwright.Locator
+
+// pageRoot 在整个页面中查找
+func pageRoot(page playwright.Page) locatorRoot {
+	return func(selector string) playwright.Locator {
+		return page.Locator(selector)
+	}
+}
+
+// scopeRoot 根据选择器的 frame 配置返回元素查找的根，未配置 frame 时在整个页面中查找
+func scopeRoot(page playwright.Page, selector SelectorConfig) (locatorRoot, error) {
+	frame := selector.Frame
+	if frame == nil {
+		if selector.Scope == "frame" {
+			return nil, fmt.Errorf("scope 为 frame 时需要提供 frame（name、url 或 selector）")
+		}
+		return pageRoot(page), nil
+	}
+
+	configured := 0
+	for _, value := range []string{frame.Name, frame.URL, frame.Selector} {
+		if value != "" {
+			configured++
+		}
+	}
+	if configured != 1 {
+		return nil, fmt.Errorf("frame 需要且只能配置 name、url、selector 中的一个")
+	}
+
+	switch {
+	case frame.Name != "":
+		name := cssString(frame.Name)
+		sel := fmt.Sprintf("iframe[name=%s], frame[name=%s]", name, name)
+		return frameLocatorRoot(page.Locator(sel).First().ContentFrame()), nil
+	case frame.Selector != "":
+		return frameLocatorRoot(page.Locator(frame.Selector).First().ContentFrame()), nil
+	default:
+		return findFrameByURL(page, frame.URL)
+	}
+}
+
+// cssString 将值转换为 CSS 属性选择器中的双引号字符串，转义其中的反斜杠和双引号
+func cssString(value string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value) + `"`
+}
+
+func frameLocatorRoot(frame playwright.FrameLocator) locatorRoot {
+	return func(selector string) playwright.Locator {
+		return frame.Locator(selector)
+	}
+}
+
+// findFrameByURL 查找地址匹配的 iframe，未找到时等待其加载
+func findFrameByURL(page playwright.Page, pattern string) (locatorRoot, error) {
+	re, err := CompileURLPattern(pattern)
+	if err != nil {
+		return nil, err
+	}
+	deadline := time.Now().Add(frameWaitTimeout)
+	for {
+		var urls []string
+		for _, frame := range page.Frames() {
+			if frame == page.MainFrame() {
+				continue
+			}
+			if re.MatchString(frame.URL()) {
+				return func(selector string) playwright.Locator {
+					return frame.Locator(selector)
+				}, nil
+			}
+			urls = append(urls, frame.URL())
+		}
+		if time.Now().After(deadline) {
+			if len(urls) == 0 {
+				return nil, fmt.Errorf("未找到 url 匹配 '%s' 的 iframe: 页面中没有 iframe", pattern)
+			}
+			return nil, fmt.Errorf("未找到 url 匹配 '%s' 的 iframe，当前 iframe: %s", pattern, strings.Join(urls, ", "))
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+// ScopeLocator 在选择器所在的范围（页面或 iframe）中创建定位器
+func ScopeLocator(page playwright.Page, selector SelectorConfig, s string) (playwright.Locator, error) {
+	root, err := scopeRoot(page, selector)
+	if err != nil {
+		return nil, err
+	}
+	return root(s), nil
+}
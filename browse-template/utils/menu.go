@@ -3,14 +3,13 @@ package utils
 import (
 	"fmt"
 	"strings"
-	"time"
 
 	"github.com/playwright-community/playwright-go"
 )
 
 // ClickMenu 点击多级菜单
-// menuPath 格式: "系统管理 > 用户管理 > 新增用户"
-func ClickMenu(page playwright.Page, menuPath string) error {
+// menuPath 格式: "系统管理 > 用户管理 > 新增用户"；timeout 为等待子菜单出现的超时时间（毫秒）
+func ClickMenu(page playwright.Page, menuPath string, timeout float64) error {
 	// 分割菜单路径
 	menuItems := strings.Split(menuPath, ">")
 	if len(menuItems) == 0 {
@@ -29,36 +28,31 @@ func ClickMenu(page playwright.Page, menuPath string) error {
 	for i, menuText := range menuItems {
 		fmt.Printf("    点击菜单项 [%d/%d]: %s\n", i+1, len(menuItems), menuText)
 
+		// 子菜单需要等待上一级展开后才会出现
+		if i > 0 {
+			waitForVisibleText(pageRoot(page), menuText, timeout)
+		}
+
 		// 定位菜单项
 		element, err := locateMenuElement(page, menuText)
 		if err != nil {
 			return fmt.Errorf("定位菜单项 '%s' 失败: %v", menuText, err)
 		}
 
-		// 等待元素可见
+		// 不可见时尝试悬停以展开菜单
 		visible, err := element.IsVisible()
 		if err != nil || !visible {
-			// 尝试悬停以展开菜单
 			err = element.Hover()
 			if err != nil {
 				return fmt.Errorf("悬停菜单项 '%s' 失败: %v", menuText, err)
 			}
-			time.Sleep(200 * time.Millisecond)
 		}
 
-		// 点击菜单项
+		// 点击菜单项（Click 会自动等待元素可见、稳定、可用）
 		err = element.Click()
 		if err != nil {
 			return fmt.Errorf("点击菜单项 '%s' 失败: %v", menuText, err)
 		}
-
-		// 等待菜单展开/页面响应
-		time.Sleep(300 * time.Millisecond)
-
-		// 如果不是最后一项，等待子菜单出现
-		if i < len(menuItems)-1 {
-			time.Sleep(200 * time.Millisecond)
-		}
 	}
 
 	return nil
@@ -3,7 +3,6 @@ package utils
 import (
 	"fmt"
 	"strings"
-	"time"
 
 	"github.com/playwright-community/playwright-go"
 )
@@ -31,13 +30,23 @@ type TableColumnConfig struct {
 // 根据条件查找表格中的行，返回行元素
 // 如果 tableSelector 为空，则在当前页面查找所有表格
 func FindTableRow(page playwright.Page, tableSelector SelectorConfig, rowConfig TableRowConfig) (playwright.ElementHandle, error) {
+	// 表格在选择器所在的页面或 iframe 中查找
+	root, err := scopeRoot(page, tableSelector)
+	if err != nil {
+		return nil, err
+	}
+	return findTableRow(root, tableSelector, rowConfig)
+}
+
+// findTableRow 在已确定的查找范围（页面或 iframe）中查找表格行
+func findTableRow(root locatorRoot, tableSelector SelectorConfig, rowConfig TableRowConfig) (playwright.ElementHandle, error) {
 	// 获取表格的定位器
 	var tableLocator playwright.Locator
 
 	// 如果未指定表格选择器，默认查找页面中的第一个表格
 	if tableSelector.Type == "" || tableSelector.Value == "" {
 		// 查找页面中的第一个表格
-		tableLocator = page.Locator("table").First()
+		tableLocator = root("table").First()
 		count, err := tableLocator.Count()
 		if err != nil || count == 0 {
 			return nil, fmt.Errorf("当前页面未找到表格")
@@ -47,13 +56,13 @@ func FindTableRow(page playwright.Page, tableSelector SelectorConfig, rowConfig
 		switch tableSelector.Type {
 		case "text":
 			// 通过文本定位表格（通常是表格标题或label）
-			tableLocator = page.Locator(fmt.Sprintf("//table[.//th[contains(text(), '%s')]]", tableSelector.Value))
+			tableLocator = root(fmt.Sprintf("//table[.//th[contains(text(), '%s')]]", tableSelector.Value))
 		case "css", "id":
-			tableLocator = page.Locator(tableSelector.Value)
+			tableLocator = root(tableSelector.Value)
 		case "xpath":
-			tableLocator = page.Locator(tableSelector.Value)
+			tableLocator = root(tableSelector.Value)
 		default:
-			tableLocator = page.Locator(tableSelector.Value)
+			tableLocator = root(tableSelector.Value)
 		}
 
 		// 验证表格是否存在
@@ -124,8 +133,14 @@ func FindTableRow(page playwright.Page, tableSelector SelectorConfig, rowConfig
 
 // FindTableCell 查找表格单元格
 func FindTableCell(page playwright.Page, tableSelector SelectorConfig, rowConfig TableRowConfig, columnConfig TableColumnConfig) (playwright.ElementHandle, error) {
+	// 表格在选择器所在的页面或 iframe 中查找
+	root, err := scopeRoot(page, tableSelector)
+	if err != nil {
+		return nil, err
+	}
+
 	// 先找到行
-	rowElement, err := FindTableRow(page, tableSelector, rowConfig)
+	rowElement, err := findTableRow(root, tableSelector, rowConfig)
 	if err != nil {
 		return nil, err
 	}
@@ -142,9 +157,9 @@ func FindTableCell(page playwright.Page, tableSelector SelectorConfig, rowConfig
 		var tableLocator playwright.Locator
 		if tableSelector.Type == "" || tableSelector.Value == "" {
 			// 如果未指定表格选择器，使用页面中的第一个表格
-			tableLocator = page.Locator("table").First()
+			tableLocator = root("table").First()
 		} else {
-			tableLocator = page.Locator(tableSelector.Value)
+			tableLocator = root(tableSelector.Value)
 		}
 		headers := tableLocator.Locator("thead th, th")
 		count, err := headers.Count()
@@ -176,14 +191,14 @@ func FindTableCell(page playwright.Page, tableSelector SelectorConfig, rowConfig
 	var tableLocator playwright.Locator
 	switch tableSelector.Type {
 	case "css", "id":
-		tableLocator = page.Locator(tableSelector.Value)
+		tableLocator = root(tableSelector.Value)
 	case "xpath":
-		tableLocator = page.Locator(tableSelector.Value)
+		tableLocator = root(tableSelector.Value)
 	default:
 		if tableSelector.Value == "" {
-			tableLocator = page.Locator("table").First()
+			tableLocator = root("table").First()
 		} else {
-			tableLocator = page.Locator(tableSelector.Value)
+			tableLocator = root(tableSelector.Value)
 		}
 	}
 
@@ -210,8 +225,14 @@ func FindTableCell(page playwright.Page, tableSelector SelectorConfig, rowConfig
 
 // ClickTableAction 点击表格中的操作按钮（编辑、删除等）
 func ClickTableAction(page playwright.Page, tableSelector SelectorConfig, rowConfig TableRowConfig, actionText string) error {
+	// 表格在选择器所在的页面或 iframe 中查找
+	root, err := scopeRoot(page, tableSelector)
+	if err != nil {
+		return err
+	}
+
 	// 找到行
-	rowElement, err := FindTableRow(page, tableSelector, rowConfig)
+	rowElement, err := findTableRow(root, tableSelector, rowConfig)
 	if err != nil {
 		return err
 	}
@@ -223,11 +244,11 @@ func ClickTableAction(page playwright.Page, tableSelector SelectorConfig, rowCon
 	var tableLocator playwright.Locator
 	switch tableSelector.Type {
 	case "css", "id":
-		tableLocator = page.Locator(tableSelector.Value)
+		tableLocator = root(tableSelector.Value)
 	case "xpath":
-		tableLocator = page.Locator(tableSelector.Value)
+		tableLocator = root(tableSelector.Value)
 	default:
-		tableLocator = page.Locator(tableSelector.Value)
+		tableLocator = root(tableSelector.Value)
 	}
 
 	rows := tableLocator.Locator("tbody tr, tr")
@@ -254,7 +275,6 @@ func ClickTableAction(page playwright.Page, tableSelector SelectorConfig, rowCon
 				if err == nil && actionCount > 0 {
 					err = actionLocator.First().Click()
 					if err == nil {
-						time.Sleep(300 * time.Millisecond)
 						return nil
 					}
 				}
@@ -268,8 +288,14 @@ func ClickTableAction(page playwright.Page, tableSelector SelectorConfig, rowCon
 
 // GetTableRowData 获取表格行数据
 func GetTableRowData(page playwright.Page, tableSelector SelectorConfig, rowConfig TableRowConfig) (map[string]string, error) {
+	// 表格在选择器所在的页面或 iframe 中查找
+	root, err := scopeRoot(page, tableSelector)
+	if err != nil {
+		return nil, err
+	}
+
 	// 找到行
-	rowElement, err := FindTableRow(page, tableSelector, rowConfig)
+	rowElement, err := findTableRow(root, tableSelector, rowConfig)
 	if err != nil {
 		return nil, err
 	}
@@ -281,14 +307,14 @@ func GetTableRowData(page playwright.Page, tableSelector SelectorConfig, rowConf
 	var tableLocator playwright.Locator
 	switch tableSelector.Type {
 	case "css", "id":
-		tableLocator = page.Locator(tableSelector.Value)
+		tableLocator = root(tableSelector.Value)
 	case "xpath":
-		tableLocator = page.Locator(tableSelector.Value)
+		tableLocator = root(tableSelector.Value)
 	default:
 		if tableSelector.Value == "" {
-			tableLocator = page.Locator("table").First()
+			tableLocator = root("table").First()
 		} else {
-			tableLocator = page.Locator(tableSelector.Value)
+			tableLocator = root(tableSelector.Value)
 		}
 	}
 
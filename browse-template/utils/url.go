@@ -0,0 +1,37 @@
+package utils
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// CompileURLPattern 将 URL 匹配规则转换为正则表达式
+// "/.../" 形式为正则表达式；包含 * 时为通配符（** 匹配任意字符，* 匹配除 / 以外的字符）；否则为包含匹配
+func CompileURLPattern(pattern string) (*regexp.Regexp, error) {
+	if len(pattern) > 2 && strings.HasPrefix(pattern, "/") && strings.HasSuffix(pattern, "/") {
+		re, err := regexp.Compile(pattern[1 : len(pattern)-1])
+		if err != nil {
+			return nil, fmt.Errorf("URL 正则表达式无效: %v", err)
+		}
+		return re, nil
+	}
+	if !strings.Contains(pattern, "*") {
+		return regexp.MustCompile(regexp.QuoteMeta(pattern)), nil
+	}
+	var expr strings.Builder
+	expr.WriteString("^")
+	for i := 0; i < len(pattern); i++ {
+		switch {
+		case strings.HasPrefix(pattern[i:], "**"):
+			expr.WriteString(".*")
+			i++
+		case pattern[i] == '*':
+			expr.WriteString("[^/]*")
+		default:
+			expr.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		}
+	}
+	expr.WriteString("$")
+	return regexp.MustCompile(expr.String()), nil
+}
@@ -0,0 +1,65 @@
+package utils
+
+import (
+	"fmt"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// DefaultLoadingMasks 默认等待消失的加载遮罩（Element UI / Ant Design）
+var DefaultLoadingMasks = []string{".el-loading-mask", ".ant-spin-spinning"}
+
+// WaitOptions 页面稳定等待配置
+type WaitOptions struct {
+	Timeout      float64  // 等待加载遮罩消失的超时时间（毫秒）
+	NetworkIdle  float64  // 等待网络空闲的最长时间（毫秒），超时不视为失败；<= 0 表示不等待
+	LoadingMasks []string // 需要等待消失的加载遮罩选择器
+}
+
+// WaitForPageSettled 等待页面稳定：网络空闲（尽力而为）且加载遮罩全部消失
+// 轮询类接口可能导致网络始终不空闲，因此网络空闲等待超时后直接继续
+func WaitForPageSettled(page playwright.Page, opts WaitOptions) error {
+	if opts.NetworkIdle > 0 {
+		_ = page.WaitForLoadState(playwright.PageWaitForLoadStateOptions{
+			State:   playwright.LoadStateNetworkidle,
+			Timeout: playwright.Float(opts.NetworkIdle),
+		})
+	}
+	return WaitForLoadingMasks(page, opts.LoadingMasks, opts.Timeout)
+}
+
+// WaitForLoadingMasks 等待所有可见的加载遮罩消失
+func WaitForLoadingMasks(page playwright.Page, masks []string, timeout float64) error {
+	for _, mask := range masks {
+		// 只关注可见的遮罩，First 会在每次检查时重新解析，直到没有任何可见遮罩
+		locator := page.Locator(mask + " >> visible=true").First()
+		err := locator.WaitFor(playwright.LocatorWaitForOptions{
+			State:   playwright.WaitForSelectorStateHidden,
+			Timeout: playwright.Float(timeout),
+		})
+		if err != nil {
+			return fmt.Errorf("等待加载遮罩 '%s' 消失超时: %v", mask, err)
+		}
+	}
+	return nil
+}
+
+// WaitForElementReady 等待元素可见且可用（未禁用）
+func WaitForElementReady(element playwright.ElementHandle, timeout float64) error {
+	options := playwright.ElementHandleWaitForElementStateOptions{Timeout: playwright.Float(timeout)}
+	if err := element.WaitForElementState(*playwright.ElementStateVisible, options); err != nil {
+		return fmt.Errorf("等待元素可见超时: %v", err)
+	}
+	if err := element.WaitForElementState(*playwright.ElementStateEnabled, options); err != nil {
+		return fmt.Errorf("等待元素可用超时: %v", err)
+	}
+	return nil
+}
+
+// waitForVisibleText 等待包含指定文本的可见元素出现（用于菜单展开、下拉框弹出），timeout 为毫秒
+// 等待失败不视为错误，由后续定位逻辑给出具体的失败原因
+func waitForVisibleText(root locatorRoot, text string, timeout float64) {
+	_ = root(fmt.Sprintf("text=%s >> visible=true", text)).First().WaitFor(playwright.LocatorWaitForOptions{
+		Timeout: playwright.Float(timeout),
+	})
+}
@@ -4,21 +4,55 @@ import (
 	apistemplate "autotest/apis-template"
 	browseTemplate "autotest/browse-template"
 	"autotest/runner"
+	toolsTemplate "autotest/tools-template"
 
 	"bufio"
 	"flag"
 	"fmt"
+	"io"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"strings"
 	"syscall"
 )
 
+// reportFlags 可重复指定的 -report 参数
+type reportFlags []runner.ReportSpec
+
+func (f *reportFlags) String() string {
+	specs := make([]string, 0, len(*f))
+	for _, spec := range *f {
+		specs = append(specs, spec.Format+"="+spec.Path)
+	}
+	return strings.Join(specs, ",")
+}
+
+func (f *reportFlags) Set(value string) error {
+	spec, err := runner.ParseReportSpec(value)
+	if err != nil {
+		return err
+	}
+	*f = append(*f, spec)
+	return nil
+}
+
 func main() {
+	// 子命令: import <format> <file> [选项]
+	if len(os.Args) > 1 && os.Args[1] == "import" {
+		os.Exit(runImport(os.Args[2:]))
+	}
+
 	// 定义命令行参数
+	var reports reportFlags
+	flag.Var(&reports, "report", "输出报告，格式 format=path，可重复指定，例如 -report junit=report.xml")
 	var (
 		browseConfigFile = flag.String("c", "browse-template/browse-config.yaml", "配置playright浏览器文件路径")
 		apiTemplateFile  = flag.String("a", "apis-template/apis.json", "API模板文件路径")
+		toolTemplateFile = flag.String("t", "tools-template/tools.json", "系统工具模板文件路径")
 		testFile         = flag.String("f", "testcase/apis/api_test.json", "测试用例文件路径")
+		parallel         = flag.Int("parallel", 1, "并行执行的用例数（每个 worker 使用独立的浏览器上下文）")
+		failFast         = flag.Bool("fail-fast", false, "遇到第一个失败用例后停止执行（覆盖配置文件中的 fail_fast）")
 		help             = flag.Bool("h", false, "显示帮助信息")
 	)
 
@@ -54,34 +88,181 @@ func main() {
 		apiTemplates = make(apistemplate.APITemplates) // 空 map 防止空指针
 	}
 
-	// 启动 Playwright 浏览器
-	page := browseTemplate.StartWithConfig(cfg)
+	// 3. 加载 System Tool 模板
+	fmt.Printf("📋 加载工具模板: %s\n", *toolTemplateFile)
+	toolTemplates, err := toolsTemplate.LoadToolTemplates(*toolTemplateFile)
+	if err != nil {
+		fmt.Printf("⚠️  工具模板加载失败 (如果没有 tool_config 用例请忽略): %v\n", err)
+		toolTemplates = make(toolsTemplate.ToolTemplates)
+	}
+
+	// 启动 Playwright 浏览器（每个 UI 用例使用独立的浏览器上下文）
+	browseTemplate.StartBrowser(cfg)
 	// 根据配置决定是否在测试结束后关闭浏览器
 	if !cfg.KeepBrowserOpen {
 		defer browseTemplate.Stop()
 	}
 
 	// 创建测试运行器
-	testRunner := runner.NewRunner(page, apiTemplates)
+	testRunner := runner.NewRunner(nil, apiTemplates)
+	testRunner.SetBrowserConfig(cfg)
+	apiClient, err := apistemplate.NewClient(cfg.APIClientConfig())
+	if err != nil {
+		fmt.Printf("❌ API 客户端配置错误: %v\n", err)
+		exitOrWait(cfg, 1)
+		return
+	}
+	testRunner.SetAPIClient(apiClient)
+	testRunner.SetAuthProfiles(cfg.AuthProfiles)
+	testRunner.SetContextFactory(browseTemplate.NewContext)
+	testRunner.SetKeepContextOpen(cfg.KeepBrowserOpen)
+	testRunner.SetToolTemplates(toolTemplates)
+	testRunner.SetFailFast(cfg.FailFast || *failFast)
+	testRunner.SetParallel(*parallel)
 
 	// 执行测试套件
 	fmt.Printf("📂 加载测试文件: %s\n", *testFile)
-	err = testRunner.RunTestSuiteFromFile(*testFile)
+	result, err := testRunner.RunTestSuiteFromFile(*testFile)
 	if err != nil {
 		fmt.Printf("❌ 测试执行失败: %v\n", err)
-		browseTemplate.TakeErrorScreenshot(page)
-		if cfg.KeepBrowserOpen {
-			waitForUserInput("浏览器将保持打开状态，请按 Enter 键退出程序")
-		} else {
-			os.Exit(1)
+		exitOrWait(cfg, 1)
+		return
+	}
+
+	result.PrintSummary()
+	for _, spec := range reports {
+		if err := runner.WriteReport(spec, result); err != nil {
+			fmt.Printf("⚠️  报告输出失败: %v\n", err)
+			continue
 		}
+		fmt.Printf("📄 已生成 %s 报告: %s\n", spec.Format, spec.Path)
+	}
+	if result.HasFailures() {
+		fmt.Println("❌ 存在失败的测试用例")
+		exitOrWait(cfg, 1)
 		return
 	}
 
 	fmt.Println("✅ 所有测试用例执行完成")
+	exitOrWait(cfg, 0)
+}
+
+// runImport 执行 import 子命令，将接口文档转换为 API 模板（合并到已有模板文件）与骨架用例，返回退出码
+func runImport(args []string) int {
+	usage := func() {
+		fmt.Println("用法: import openapi <spec.yaml|spec.json> [-o apis.json] [-cases 用例文件] [--force]")
+		fmt.Println("      import postman <collection.json> [-o apis.json] [--force]")
+		fmt.Println("      import curl <命令文件|-> [-name 模板名] [-o apis.json] [--force]")
+	}
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		usage()
+		return 2
+	}
+	format := args[0]
+
+	fs := flag.NewFlagSet("import "+format, flag.ContinueOnError)
+	output := fs.String("o", "apis-template/apis.json", "输出的 API 模板文件，已存在时合并")
+	casesFile := fs.String("cases", "", "输出的骨架用例文件（默认: testcase/apis/<文档名>_test.json）")
+	name := fs.String("name", "", "curl 导入的模板名（默认由方法与路径生成）")
+	force := fs.Bool("force", false, "覆盖已存在的同名模板")
+	// 输入文件可以写在选项之前或之后
+	if err := fs.Parse(args[1:]); err != nil {
+		return 2
+	}
+	input := fs.Arg(0)
+	if fs.NArg() > 0 {
+		if err := fs.Parse(fs.Args()[1:]); err != nil {
+			return 2
+		}
+	}
+	if input == "" {
+		fmt.Printf("❌ 缺少输入文件: import %s <文件>\n", format)
+		return 2
+	}
+
+	var data []byte
+	var err error
+	if input == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(input)
+	}
+	if err != nil {
+		fmt.Printf("❌ 读取文件失败: %v\n", err)
+		return 1
+	}
+
+	var result *apistemplate.ImportResult
+	switch format {
+	case "openapi", "swagger":
+		result, err = apistemplate.ImportOpenAPI(data)
+	case "postman":
+		result, err = apistemplate.ImportPostman(data)
+	case "curl":
+		result, err = apistemplate.ImportCurl(string(data), *name)
+	default:
+		fmt.Printf("❌ 不支持的导入格式: %s（支持: openapi, postman, curl）\n", format)
+		return 2
+	}
+	if err != nil {
+		fmt.Printf("❌ 导入失败: %v\n", err)
+		return 1
+	}
+	for _, warning := range result.Warnings {
+		fmt.Printf("⚠️  %s\n", warning)
+	}
+
+	merged, err := apistemplate.MergeAPITemplates(*output, result.Templates, *force)
+	if err != nil {
+		fmt.Printf("❌ 写入 API 模板失败: %v\n", err)
+		return 1
+	}
+	for _, key := range merged.Skipped {
+		fmt.Printf("⏭️  模板已存在，跳过: %s（使用 --force 覆盖）\n", key)
+	}
+	for _, key := range merged.Replaced {
+		fmt.Printf("♻️  覆盖模板: %s\n", key)
+	}
+	fmt.Printf("📄 新增 %d 个、覆盖 %d 个、跳过 %d 个 API 模板: %s\n",
+		len(merged.Added), len(merged.Replaced), len(merged.Skipped), *output)
+
+	// 骨架用例只为新增的模板生成，追加到已有用例文件末尾
+	added := make(map[string]bool, len(merged.Added))
+	for _, key := range merged.Added {
+		added[key] = true
+	}
+	var cases []apistemplate.TestCase
+	for _, c := range result.Cases {
+		if added[c.APIConfig.Template] {
+			cases = append(cases, c)
+		}
+	}
+	if len(cases) > 0 {
+		if *casesFile == "" {
+			base := strings.TrimSuffix(filepath.Base(input), filepath.Ext(input))
+			*casesFile = filepath.Join("testcase", "apis", base+"_test.json")
+		}
+		if err := apistemplate.AppendTestCases(*casesFile, cases); err != nil {
+			fmt.Printf("❌ 写入骨架用例失败: %v\n", err)
+			return 1
+		}
+		fmt.Printf("📄 已生成 %d 个骨架用例: %s\n", len(cases), *casesFile)
+	}
+	return 0
+}
+
+// exitOrWait 根据配置决定保持浏览器打开等待用户输入，或以指定退出码退出
+func exitOrWait(cfg *browseTemplate.Config, code int) {
 	if cfg.KeepBrowserOpen {
 		waitForUserInput("浏览器将保持打开状态，请按 Enter 键退出程序")
 	}
+	if code != 0 {
+		// os.Exit 不会执行 defer，需要手动关闭浏览器
+		if !cfg.KeepBrowserOpen {
+			browseTemplate.Stop()
+		}
+		os.Exit(code)
+	}
 }
 
 // waitForUserInput 等待用户输入或信号，保持程序运行
@@ -119,10 +300,26 @@ func showHelp() {
 	fmt.Println("选项:")
 	fmt.Println("  -c string    配置文件路径 (默认: config.yaml)")
 	fmt.Println("  -f string    测试用例文件路径 (默认: testcase/login_example.json)")
+	fmt.Println("  -t string    系统工具模板文件路径 (默认: tools-template/tools.json)")
+	fmt.Println("  -parallel N  并行执行的用例数 (默认: 1，顺序执行)")
+	fmt.Println("  -fail-fast   遇到第一个失败用例后停止执行")
+	fmt.Println("  -report      输出报告，格式 format=path，可重复指定 (支持: junit, html)")
 	fmt.Println("  -h           显示帮助信息")
 	fmt.Println()
+	fmt.Println("子命令:")
+	fmt.Println("  import openapi <spec.yaml> [-o apis.json] [-cases 用例文件] [--force]")
+	fmt.Println("               由 OpenAPI / Swagger 文档生成 API 模板与骨架用例")
+	fmt.Println("  import postman <collection.json> [-o apis.json] [--force]")
+	fmt.Println("               由 Postman v2.1 集合生成 API 模板")
+	fmt.Println("  import curl <命令文件|-> [-name 模板名] [-o apis.json] [--force]")
+	fmt.Println("               由 curl 命令生成 API 模板（- 表示从标准输入读取）")
+	fmt.Println("               导入的模板合并到已有模板文件，同名模板默认跳过，--force 覆盖")
+	fmt.Println()
 	fmt.Println("示例:")
 	fmt.Println("  go run main.go -c config.yaml -f testcase/login_example.json")
 	fmt.Println("  go run main.go -f testcase/my_test.json")
 	fmt.Println("  go run main.go -c my_config.yaml")
+	fmt.Println("  go run main.go -f testcase/my_test.json -report junit=build/junit.xml -report html=build/report.html")
+	fmt.Println("  go run main.go import openapi docs/openapi.yaml -o apis-template/apis.json")
+	fmt.Println("  go run main.go import postman docs/collection.json --force")
 }
@@ -0,0 +1,119 @@
+package runner
+
+import (
+	browseTemplate "autotest/browse-template"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// DefaultStorageStateDir 登录状态文件的保存目录
+const DefaultStorageStateDir = "assets/storage"
+
+// ContextFactory 创建浏览器上下文及页面，storageStatePath 非空时加载已保存的登录状态
+type ContextFactory func(storageStatePath string) (playwright.BrowserContext, playwright.Page, error)
+
+// SetContextFactory 设置浏览器上下文工厂
+// 设置后每个 UI 用例都会使用全新的上下文，Cookie、localStorage 不会在用例间泄漏
+func (r *Runner) SetContextFactory(factory ContextFactory) {
+	r.newContext = factory
+}
+
+// SetKeepContextOpen 设置用例结束后是否保留其浏览器上下文（调试用）
+func (r *Runner) SetKeepContextOpen(keep bool) {
+	r.keepContextOpen = keep
+}
+
+// storageStatePath 返回命名登录状态对应的文件路径
+func (r *Runner) storageStatePath(name string) string {
+	return filepath.Join(DefaultStorageStateDir, name+".json")
+}
+
+// openContext 为 UI 用例创建独立的浏览器上下文
+// 未设置 ContextFactory 时沿用 NewRunner 传入的共享页面
+func (r *Runner) openContext(testCase TestCase) error {
+	if r.newContext == nil {
+		if testCase.UseStorageState != "" || testCase.SaveStorageState != "" {
+			fmt.Fprintln(r.out, "  ⚠️  当前为共享页面模式，忽略 use_storage_state / save_storage_state")
+		}
+		return nil
+	}
+
+	statePath := ""
+	if testCase.UseStorageState != "" {
+		statePath = r.storageStatePath(testCase.UseStorageState)
+		if _, err := os.Stat(statePath); err != nil {
+			return fmt.Errorf("登录状态 '%s' 不存在，请先执行带有 save_storage_state: \"%s\" 的用例", testCase.UseStorageState, testCase.UseStorageState)
+		}
+		fmt.Fprintf(r.out, "  🔑 复用登录状态: %s\n", testCase.UseStorageState)
+	}
+
+	ctx, page, err := r.newContext(statePath)
+	if err != nil {
+		return err
+	}
+	if err := r.setupHAR(ctx, testCase.Name); err != nil {
+		ctx.Close()
+		return err
+	}
+	r.context = ctx
+	r.page = page
+	r.video = ""
+	r.videoResolved = false
+	return nil
+}
+
+// setupHAR 按配置为用例的上下文录制或回放 HAR，录制的文件在上下文关闭时写入
+func (r *Runner) setupHAR(ctx playwright.BrowserContext, caseName string) error {
+	switch {
+	case r.config.RecordHAR != "":
+		path := browseTemplate.HARPath(r.config.RecordHAR, caseName)
+		if err := browseTemplate.RecordHAR(ctx, path); err != nil {
+			return err
+		}
+		if r.keepContextOpen {
+			fmt.Fprintln(r.out, "  ⚠️  keep_browser_open 时上下文不会关闭，HAR 不会写入")
+		}
+		fmt.Fprintf(r.out, "  📼 录制 HAR: %s\n", path)
+	case r.config.ReplayHAR != "":
+		path := browseTemplate.HARPath(r.config.ReplayHAR, caseName)
+		notFound := r.config.HARNotFound
+		if notFound == "" {
+			notFound = browseTemplate.HARNotFoundAbort
+		}
+		if err := browseTemplate.ReplayHAR(ctx, path, notFound); err != nil {
+			return err
+		}
+		fmt.Fprintf(r.out, "  📼 回放 HAR: %s (未命中的请求: %s)\n", path, notFound)
+	}
+	return nil
+}
+
+// saveStorageState 保存当前上下文的登录状态
+func (r *Runner) saveStorageState(name string) error {
+	if r.context == nil {
+		return nil
+	}
+	path := r.storageStatePath(name)
+	if err := browseTemplate.SaveStorageState(r.context, path); err != nil {
+		return err
+	}
+	fmt.Fprintf(r.out, "  🔑 已保存登录状态: %s -> %s\n", name, path)
+	return nil
+}
+
+// closeContext 关闭用例的浏览器上下文（关闭后录屏文件才会完整写入）
+func (r *Runner) closeContext() {
+	if r.newContext == nil || r.context == nil {
+		return
+	}
+	if !r.keepContextOpen {
+		if err := r.context.Close(); err != nil {
+			fmt.Fprintf(r.out, "  ⚠️  关闭浏览器上下文失败: %v\n", err)
+		}
+	}
+	r.context = nil
+	r.page = nil
+}
@@ -0,0 +1,223 @@
+package runner
+
+import (
+	browseTemplate "autotest/browse-template"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// dataRow 数据驱动中的一行数据，保留列的定义顺序
+type dataRow struct {
+	Columns []string
+	Values  map[string]string
+	Typed   map[string]interface{} // JSON 数据中非字符串的值（数字、布尔、对象、数组、null），保留原始类型
+}
+
+// ExpandDataDriven 展开数据驱动用例：声明了 data 或 data_file 的用例按每行数据生成一个用例
+// 每行的列可在步骤和 api_config.params 中以 {列名} 占位符引用；baseDir 用于解析 data_file 的相对路径
+func ExpandDataDriven(suite TestSuite, baseDir string) (TestSuite, error) {
+	expanded := make(TestSuite, 0, len(suite))
+	for _, testCase := range suite {
+		if len(testCase.Data) == 0 && testCase.DataFile == "" {
+			expanded = append(expanded, testCase)
+			continue
+		}
+
+		rows, err := loadDataRows(testCase, baseDir)
+		if err != nil {
+			return nil, fmt.Errorf("用例 '%s' 加载数据失败: %v", testCase.Name, err)
+		}
+		for i, row := range rows {
+			expanded = append(expanded, applyDataRow(testCase, i+1, row))
+		}
+	}
+	return expanded, nil
+}
+
+// loadDataRows 读取内联 data 与 data_file 中的所有数据行（内联数据在前）
+func loadDataRows(testCase TestCase, baseDir string) ([]dataRow, error) {
+	var rows []dataRow
+	for i, raw := range testCase.Data {
+		row, err := parseJSONRow(raw)
+		if err != nil {
+			return nil, fmt.Errorf("data 第 %d 行解析失败: %v", i+1, err)
+		}
+		rows = append(rows, row)
+	}
+
+	if testCase.DataFile != "" {
+		path := testCase.DataFile
+		if !filepath.IsAbs(path) && baseDir != "" {
+			path = filepath.Join(baseDir, path)
+		}
+		fileRows, err := loadDataFile(path)
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, fileRows...)
+	}
+
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("没有数据行")
+	}
+	return rows, nil
+}
+
+// loadDataFile 读取 CSV（首行为列名）或 JSON（对象数组）数据文件
+func loadDataFile(path string) ([]dataRow, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取数据文件失败: %v", err)
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return parseCSVRows(content)
+	case ".json":
+		var raws []json.RawMessage
+		if err := json.Unmarshal(content, &raws); err != nil {
+			return nil, fmt.Errorf("解析数据文件失败: %v", err)
+		}
+		rows := make([]dataRow, 0, len(raws))
+		for i, raw := range raws {
+			row, err := parseJSONRow(raw)
+			if err != nil {
+				return nil, fmt.Errorf("数据文件第 %d 行解析失败: %v", i+1, err)
+			}
+			rows = append(rows, row)
+		}
+		return rows, nil
+	default:
+		return nil, fmt.Errorf("不支持的数据文件格式: %s（仅支持 .csv 和 .json）", path)
+	}
+}
+
+// parseCSVRows 解析 CSV，首行为列名
+func parseCSVRows(content []byte) ([]dataRow, error) {
+	content = bytes.TrimPrefix(content, []byte("\xef\xbb\xbf")) // 去掉 Excel 导出的 BOM
+	records, err := csv.NewReader(bytes.NewReader(content)).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("解析 CSV 失败: %v", err)
+	}
+	if len(records) < 2 {
+		return nil, fmt.Errorf("CSV 至少需要列名行和一行数据")
+	}
+
+	header := make([]string, len(records[0]))
+	for i, col := range records[0] {
+		header[i] = strings.TrimSpace(col)
+	}
+
+	rows := make([]dataRow, 0, len(records)-1)
+	for _, record := range records[1:] {
+		row := dataRow{Columns: header, Values: make(map[string]string, len(header))}
+		for i, col := range header {
+			if i < len(record) {
+				row.Values[col] = record[i]
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// parseJSONRow 解析 JSON 对象形式的数据行，按出现顺序记录列名
+func parseJSONRow(raw json.RawMessage) (dataRow, error) {
+	row := dataRow{Values: make(map[string]string), Typed: make(map[string]interface{})}
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+
+	token, err := decoder.Token()
+	if err != nil {
+		return row, err
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '{' {
+		return row, fmt.Errorf("数据行必须是 JSON 对象")
+	}
+	for decoder.More() {
+		keyToken, err := decoder.Token()
+		if err != nil {
+			return row, err
+		}
+		key := keyToken.(string)
+
+		var value interface{}
+		if err := decoder.Decode(&value); err != nil {
+			return row, err
+		}
+		if _, exists := row.Values[key]; !exists {
+			row.Columns = append(row.Columns, key)
+		}
+		row.Values[key] = stringifyDataValue(value)
+		if _, ok := value.(string); !ok {
+			row.Typed[key] = value
+		} else {
+			delete(row.Typed, key)
+		}
+	}
+	return row, nil
+}
+
+func stringifyDataValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	default:
+		bytes, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprintf("%v", v)
+		}
+		return string(bytes)
+	}
+}
+
+// applyDataRow 生成某一行数据对应的用例副本，并将 {列名} 占位符替换为该行的值
+func applyDataRow(testCase TestCase, index int, row dataRow) TestCase {
+	rowVars := NewVariables()
+	for _, col := range row.Columns {
+		// JSON 数据中的数字、布尔等值保留类型，独占 data / query 中的整个字符串时按原类型替换
+		if value, ok := row.Typed[col]; ok {
+			rowVars.SetValue(col, value)
+		} else {
+			rowVars.Set(col, row.Values[col])
+		}
+	}
+
+	label := ""
+	if len(row.Columns) > 0 {
+		label = row.Values[row.Columns[0]]
+	}
+	testCase.Name = fmt.Sprintf("%s [row %d: %s]", testCase.Name, index, label)
+	testCase.Data = nil
+	testCase.DataFile = ""
+
+	if len(testCase.Steps) > 0 {
+		steps := make([]browseTemplate.TestStep, len(testCase.Steps))
+		for i, step := range testCase.Steps {
+			steps[i] = rowVars.resolveStep(step)
+		}
+		testCase.Steps = steps
+	}
+
+	// 行数据同时作为 {列名} 参数追加，模板中的同名占位符也会被替换
+	if testCase.APIConfig != nil {
+		apiConfig := rowVars.resolveAPIConfig(*testCase.APIConfig)
+		testCase.APIConfig = &apiConfig
+	}
+	testCase.APIExpect = rowVars.resolveExpect(testCase.APIExpect)
+	if testCase.ToolConfig != nil {
+		toolConfig := *testCase.ToolConfig
+		toolConfig.Params = rowVars.resolveParams(toolConfig.Params)
+		testCase.ToolConfig = &toolConfig
+	}
+	return testCase
+}
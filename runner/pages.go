@@ -0,0 +1,255 @@
+package runner
+
+import (
+	browseTemplate "autotest/browse-template"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// pageTracker 记录 UI 用例执行期间浏览器上下文中新打开的页面（新标签页、window.open 弹出的窗口）
+// 新页面往往由上一个步骤（如点击链接）打开，可能在 switch_page 开始前就已打开，因此需要提前记录
+type pageTracker struct {
+	mu      sync.Mutex
+	context playwright.BrowserContext
+	main    playwright.Page   // 用例开始时的页面，用例结束后恢复
+	popups  []playwright.Page // 新打开、尚未切换过的页面
+	history []playwright.Page // 切换过的页面，关闭当前页面后回到上一个
+	opened  []playwright.Page // 用例中打开的所有页面，用例结束时关闭
+	onPage  func(playwright.Page)
+}
+
+// isPageAction 是否为页面切换步骤
+func isPageAction(action string) bool {
+	return action == "switch_page" || action == "close_page"
+}
+
+// startPages 开始记录当前 UI 用例中新打开的页面
+func (r *Runner) startPages() {
+	tracker := &pageTracker{context: r.page.Context(), main: r.page, history: []playwright.Page{r.page}}
+	tracker.onPage = func(page playwright.Page) {
+		tracker.mu.Lock()
+		defer tracker.mu.Unlock()
+		tracker.popups = append(tracker.popups, page)
+		tracker.opened = append(tracker.opened, page)
+	}
+	tracker.context.OnPage(tracker.onPage)
+	r.pages = tracker
+}
+
+// stopPages 停止记录，恢复用例开始时的页面，并关闭用例中打开的其他页面
+// 共享页面模式下后续用例仍在原页面上执行，录屏也取自原页面；原页面已被关闭时保留当前页面
+func (r *Runner) stopPages() {
+	tracker := r.pages
+	tracker.context.RemoveListener("page", tracker.onPage)
+	if !tracker.main.IsClosed() {
+		r.page = tracker.main
+	}
+
+	tracker.mu.Lock()
+	opened := tracker.opened
+	tracker.mu.Unlock()
+	for _, page := range opened {
+		if page != r.page && !page.IsClosed() {
+			_ = page.Close()
+		}
+	}
+	r.pages = nil
+}
+
+// find 查找配置匹配的页面，没有匹配的页面时返回 nil
+// popup 取出最早打开、尚未切换过的页面
+func (t *pageTracker) find(config browseTemplate.PageConfig) (playwright.Page, error) {
+	if config.Popup {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		for len(t.popups) > 0 {
+			page := t.popups[0]
+			t.popups = t.popups[1:]
+			if !page.IsClosed() {
+				return page, nil
+			}
+		}
+		return nil, nil
+	}
+
+	pages := t.context.Pages()
+	if config.Index > 0 {
+		if config.Index <= len(pages) {
+			return pages[config.Index-1], nil
+		}
+		return nil, nil
+	}
+
+	pattern := config.URL
+	if config.Title != "" {
+		pattern = config.Title
+	}
+	match, err := newURLMatcher(pattern)
+	if err != nil {
+		return nil, err
+	}
+	for _, page := range pages {
+		value := page.URL()
+		if config.Title != "" {
+			if value, err = page.Title(); err != nil {
+				continue
+			}
+		}
+		if match(value) {
+			return page, nil
+		}
+	}
+	return nil, nil
+}
+
+// activate 记录切换到的页面
+func (t *pageTracker) activate(page playwright.Page) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.popups = removePage(t.popups, page)
+	t.history = append(removePage(t.history, page), page)
+}
+
+// forget 移除已关闭的页面，返回最近一个切换过且未关闭的页面
+func (t *pageTracker) forget(page playwright.Page) playwright.Page {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.popups = removePage(t.popups, page)
+	t.history = removePage(t.history, page)
+	for i := len(t.history) - 1; i >= 0; i-- {
+		if !t.history[i].IsClosed() {
+			return t.history[i]
+		}
+	}
+	return nil
+}
+
+func removePage(pages []playwright.Page, page playwright.Page) []playwright.Page {
+	var remaining []playwright.Page
+	for _, p := range pages {
+		if p != page {
+			remaining = append(remaining, p)
+		}
+	}
+	return remaining
+}
+
+// validatePageConfig 检查页面定位配置，index、title、url、popup 只能配置一个
+func validatePageConfig(config browseTemplate.PageConfig) error {
+	configured := 0
+	for _, set := range []bool{config.Index != 0, config.Title != "", config.URL != "", config.Popup} {
+		if set {
+			configured++
+		}
+	}
+	if configured != 1 {
+		return errors.New("page 需要且只能配置 index、title、url、popup 中的一个")
+	}
+	if config.Index < 0 {
+		return fmt.Errorf("page.index 从 1 开始: %d", config.Index)
+	}
+	return nil
+}
+
+// describePage 生成页面定位配置的简短描述，用于日志与报告
+func describePage(config browseTemplate.PageConfig) string {
+	switch {
+	case config.Popup:
+		return "popup"
+	case config.Index > 0:
+		return fmt.Sprintf("index=%d", config.Index)
+	case config.Title != "":
+		return "title=" + config.Title
+	default:
+		return "url=" + config.URL
+	}
+}
+
+// switchTo 将后续步骤切换到 page 上执行
+func (r *Runner) switchTo(page playwright.Page) {
+	page.SetDefaultTimeout(float64(r.config.Timeout))
+	_ = page.BringToFront()
+	r.pages.activate(page)
+	r.page = page
+	title, _ := page.Title()
+	fmt.Fprintf(r.out, "    🗂️  切换到页面: %s (%s)\n", title, page.URL())
+}
+
+// handleSwitchPage 切换到匹配的页面，之后的步骤都在该页面上执行
+func (r *Runner) handleSwitchPage(step browseTemplate.TestStep) error {
+	if step.Page == nil {
+		return errors.New("switch_page action 需要提供 page")
+	}
+	if err := validatePageConfig(*step.Page); err != nil {
+		return err
+	}
+
+	deadline := time.Now().Add(time.Duration(r.waitTimeout(step)) * time.Millisecond)
+	for {
+		page, err := r.pages.find(*step.Page)
+		if err != nil {
+			return err
+		}
+		if page != nil {
+			r.switchTo(page)
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("等待页面 %s 超时，当前页面: %s", describePage(*step.Page), r.describeOpenPages())
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+// handleClosePage 关闭当前页面或 page 匹配的页面，关闭当前页面后回到上一个切换过的页面
+func (r *Runner) handleClosePage(step browseTemplate.TestStep) error {
+	target := r.page
+	if step.Page != nil {
+		if err := validatePageConfig(*step.Page); err != nil {
+			return err
+		}
+		page, err := r.pages.find(*step.Page)
+		if err != nil {
+			return err
+		}
+		if page == nil {
+			return fmt.Errorf("没有匹配 %s 的页面，当前页面: %s", describePage(*step.Page), r.describeOpenPages())
+		}
+		target = page
+	}
+	if len(r.pages.context.Pages()) <= 1 {
+		return errors.New("不能关闭用例中的最后一个页面")
+	}
+
+	url := target.URL()
+	if err := target.Close(); err != nil {
+		return fmt.Errorf("关闭页面失败: %v", err)
+	}
+	fmt.Fprintf(r.out, "    🗂️  已关闭页面: %s\n", url)
+
+	previous := r.pages.forget(target)
+	if target != r.page {
+		return nil
+	}
+	if previous == nil {
+		// 切换过的页面都已关闭，回到最早打开的页面
+		previous = r.pages.context.Pages()[0]
+	}
+	r.switchTo(previous)
+	return nil
+}
+
+// describeOpenPages 列出上下文中打开的页面，便于给出失败原因
+func (r *Runner) describeOpenPages() string {
+	var parts []string
+	for i, page := range r.pages.context.Pages() {
+		title, _ := page.Title()
+		parts = append(parts, fmt.Sprintf("[%d] %s (%s)", i+1, title, page.URL()))
+	}
+	return strings.Join(parts, ", ")
+}
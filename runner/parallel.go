@@ -0,0 +1,153 @@
+package runner
+
+import (
+	apisTemplate "autotest/apis-template"
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+)
+
+// SetParallel 设置并行执行的 worker 数量，<= 1 表示顺序执行
+func (r *Runner) SetParallel(n int) {
+	r.parallel = n
+}
+
+// executionUnit 需要在同一 worker 中按顺序执行的一组用例（保存用例下标）
+type executionUnit []int
+
+// planSegments 将套件划分为按顺序执行的阶段，每个阶段内的执行单元可以并发
+// 以下用例作为屏障单独成为一个阶段，保证其前后顺序：
+//   - serial: true 的用例
+//   - 带 save_storage_state 的用例（后续用例可能依赖其登录状态）
+//   - 通过 save_response 保存变量的用例（后续用例可能引用这些变量）
+//   - 共享页面模式下的 UI 用例（只有一个页面，无法并发）
+//
+// 同一阶段内相同 group 的用例合并到同一个执行单元
+func (r *Runner) planSegments(suite TestSuite) [][]executionUnit {
+	var segments [][]executionUnit
+	var current []executionUnit
+	groups := make(map[string]int)
+
+	flush := func() {
+		if len(current) > 0 {
+			segments = append(segments, current)
+		}
+		current = nil
+		groups = make(map[string]int)
+	}
+
+	for i, testCase := range suite {
+		barrier := testCase.Serial || testCase.SaveStorageState != "" || r.savesVariables(testCase) ||
+			(len(testCase.Steps) > 0 && r.newContext == nil)
+		if barrier {
+			flush()
+			segments = append(segments, []executionUnit{{i}})
+			continue
+		}
+
+		if testCase.Group != "" {
+			if idx, ok := groups[testCase.Group]; ok {
+				current[idx] = append(current[idx], i)
+				continue
+			}
+			groups[testCase.Group] = len(current)
+		}
+		current = append(current, executionUnit{i})
+	}
+	flush()
+	return segments
+}
+
+// savesVariables 用例（api_config 或 wait_for_api 步骤）使用的 API 模板是否配置了 save_response
+// 运行期变量由所有 worker 共享，后续用例读取这些变量前必须等待该用例执行完成
+func (r *Runner) savesVariables(testCase TestCase) bool {
+	configs := []*apisTemplate.TestCaseConfig{testCase.APIConfig}
+	for _, step := range testCase.Steps {
+		configs = append(configs, step.API)
+	}
+	for _, config := range configs {
+		if config != nil && len(r.apiTemplates[config.Template].SaveResponse) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// fork 为 worker 创建运行器副本
+// 副本共享模板和运行期变量，但拥有独立的浏览器上下文、页面和日志输出
+func (r *Runner) fork(out io.Writer) *Runner {
+	worker := *r
+	worker.context = nil
+	worker.video = ""
+	worker.videoResolved = false
+	worker.out = out
+	return &worker
+}
+
+// runParallel 使用 worker 池执行套件，返回按用例定义顺序排列的结果
+// 每个用例的日志先写入缓冲区，执行结束后带 worker 前缀整体输出，避免日志交错
+func (r *Runner) runParallel(suite TestSuite) []*CaseResult {
+	results := make([]*CaseResult, len(suite))
+	var stopped atomic.Bool
+	var outMu sync.Mutex
+
+	fmt.Fprintf(r.out, "⚡ 并行执行: %d 个 worker\n", r.parallel)
+
+	runUnit := func(workerID int, unit executionUnit) {
+		for _, idx := range unit {
+			testCase := suite[idx]
+			if stopped.Load() {
+				results[idx] = failFastSkipped(testCase)
+				continue
+			}
+
+			var buf bytes.Buffer
+			worker := r.fork(&buf)
+			results[idx] = worker.RunTestCase(testCase)
+			if results[idx].Status == StatusFailed && r.failFast && !stopped.Swap(true) {
+				fmt.Fprintln(&buf, "⛔ fail_fast 已开启，停止执行剩余用例")
+			}
+
+			outMu.Lock()
+			writePrefixed(r.out, fmt.Sprintf("[w%d] ", workerID), buf.Bytes())
+			outMu.Unlock()
+		}
+	}
+
+	for _, segment := range r.planSegments(suite) {
+		workers := r.parallel
+		if workers > len(segment) {
+			workers = len(segment)
+		}
+
+		units := make(chan executionUnit)
+		var wg sync.WaitGroup
+		for w := 1; w <= workers; w++ {
+			wg.Add(1)
+			go func(workerID int) {
+				defer wg.Done()
+				for unit := range units {
+					runUnit(workerID, unit)
+				}
+			}(w)
+		}
+		for _, unit := range segment {
+			units <- unit
+		}
+		close(units)
+		wg.Wait()
+	}
+	return results
+}
+
+// writePrefixed 为每一行日志添加前缀后输出
+func writePrefixed(out io.Writer, prefix string, data []byte) {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+	for scanner.Scan() {
+		fmt.Fprintf(out, "%s%s\n", prefix, scanner.Text())
+	}
+}
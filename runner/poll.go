@@ -0,0 +1,74 @@
+package runner
+
+import (
+	apisTemplate "autotest/apis-template"
+	browseTemplate "autotest/browse-template"
+	"fmt"
+	"time"
+)
+
+// pollAPIRequest 重复发送同一个请求，直到响应满足 until 条件或超时
+// 报告中只保留最后一次请求的记录；超时时返回最后一次响应未满足的原因
+func (r *Runner) pollAPIRequest(req apisTemplate.APIRequest, authName string, params []apisTemplate.Param,
+	poll apisTemplate.PollConfig, expect *apisTemplate.ExpectConfig, result *CaseResult) (*apisTemplate.APIResponse, error) {
+	until := poll.Until
+	if until == nil {
+		until = expect
+	}
+	if until == nil {
+		return nil, fmt.Errorf("poll 缺少 until 条件，且未配置 expect")
+	}
+	until = r.vars.resolveExpect(until)
+
+	fmt.Fprintf(r.out, "  [API] ⏳ 轮询直到满足条件 (间隔 %v, 超时 %v)\n", poll.Interval(), poll.Timeout())
+	deadline := time.Now().Add(poll.Timeout())
+	recorded := len(result.API)
+	for attempt := 1; ; attempt++ {
+		result.API = result.API[:recorded]
+		resp, err := r.sendAPIRequest(req, authName, params, result)
+		if err == nil {
+			err = apisTemplate.ValidateResponse(resp, *until)
+		}
+		if err == nil {
+			fmt.Fprintf(r.out, "  [API] ✅ 第 %d 次请求满足条件\n", attempt)
+			return resp, nil
+		}
+
+		if time.Now().Add(poll.Interval()).After(deadline) {
+			if resp == nil {
+				return nil, fmt.Errorf("轮询超时 (%v, 共 %d 次请求)，最后一次请求失败: %v", poll.Timeout(), attempt, err)
+			}
+			return nil, fmt.Errorf("轮询超时 (%v, 共 %d 次请求)，最后一次响应 (HTTP %d) 未满足条件: %v",
+				poll.Timeout(), attempt, resp.StatusCode, err)
+		}
+		fmt.Fprintf(r.out, "  [API] 第 %d 次请求未满足条件: %v\n", attempt, err)
+		time.Sleep(poll.Interval())
+	}
+}
+
+// handleWaitForAPI 在 UI 步骤中轮询接口，等待后端状态就绪后再继续浏览器操作
+// 请求记录到当前用例结果中，save_response 提取的变量可供后续步骤引用
+func (r *Runner) handleWaitForAPI(step browseTemplate.TestStep) error {
+	if step.API == nil {
+		return fmt.Errorf("wait_for_api 缺少 api 配置")
+	}
+	config := *step.API
+	if config.Poll == nil {
+		config.Poll = &apisTemplate.PollConfig{}
+	}
+	if config.Poll.Until == nil {
+		return fmt.Errorf("wait_for_api 缺少 api.poll.until 条件")
+	}
+	if config.Poll.TimeoutMs == 0 && step.Timeout > 0 {
+		poll := *config.Poll
+		poll.TimeoutMs = step.Timeout
+		config.Poll = &poll
+	}
+
+	result := r.current
+	if result == nil {
+		result = &CaseResult{}
+	}
+	_, err := r.callAPI(config, nil, result)
+	return err
+}
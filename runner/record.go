@@ -0,0 +1,110 @@
+package runner
+
+import (
+	apisTemplate "autotest/apis-template"
+	browseTemplate "autotest/browse-template"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// describeStep 生成步骤操作对象的简短描述，用于报告展示
+func describeStep(step browseTemplate.TestStep) string {
+	var parts []string
+	if step.URL != "" {
+		parts = append(parts, step.URL)
+	}
+	if step.MenuPath != "" {
+		parts = append(parts, step.MenuPath)
+	}
+	if step.Selector != nil {
+		parts = append(parts, fmt.Sprintf("%s=%s", step.Selector.Type, step.Selector.Value))
+	}
+	for _, sel := range step.Selectors {
+		parts = append(parts, fmt.Sprintf("%s=%s", sel.Type, sel.Value))
+	}
+	if step.Table != nil && step.Table.Row != nil {
+		parts = append(parts, fmt.Sprintf("row %s=%s", step.Table.Row.Type, step.Table.Row.Value))
+	}
+	if step.Search != nil && step.Search.Button != nil {
+		parts = append(parts, fmt.Sprintf("%s=%s", step.Search.Button.Type, step.Search.Button.Value))
+	}
+	if step.State != "" {
+		parts = append(parts, "state="+step.State)
+	}
+	if step.Status != 0 {
+		parts = append(parts, fmt.Sprintf("status=%d", step.Status))
+	}
+	if step.Ms > 0 {
+		parts = append(parts, fmt.Sprintf("%dms", step.Ms))
+	}
+	if step.API != nil {
+		parts = append(parts, "api:"+step.API.Template)
+	}
+	if step.Page != nil {
+		parts = append(parts, "page "+describePage(*step.Page))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// sensitiveHeaders 记录请求时始终隐藏值的请求头（小写）
+var sensitiveHeaders = []string{"authorization", "proxy-authorization", "cookie", "x-api-key"}
+
+// redactedValue 隐藏后的凭据
+const redactedValue = "******"
+
+// newAPIExchange 根据最终请求创建 API 调用记录
+// HTML 报告会被分享，凭据类请求头以及认证配置写入的请求头 / 查询参数只记录为 ******
+func newAPIExchange(req apisTemplate.APIRequest, credentialHeader, credentialQuery string) *APIExchange {
+	if _, exists := req.Query[credentialQuery]; exists {
+		query := make(map[string]interface{}, len(req.Query))
+		for k, v := range req.Query {
+			query[k] = v
+		}
+		query[credentialQuery] = redactedValue
+		req.Query = query
+	}
+	exchange := &APIExchange{
+		Method:         strings.ToUpper(req.Method),
+		URL:            apisTemplate.BuildURL(req),
+		RequestHeaders: redactHeaders(req.Headers, credentialHeader),
+	}
+	exchange.RequestBody = apisTemplate.DescribeBody(req)
+	return exchange
+}
+
+// redactHeaders 返回隐藏了凭据的请求头副本，Authorization 保留认证方式（如 Bearer ******）
+func redactHeaders(headers map[string]string, extra string) map[string]string {
+	if len(headers) == 0 {
+		return headers
+	}
+	redacted := make(map[string]string, len(headers))
+	for key, value := range headers {
+		lower := strings.ToLower(key)
+		if !slices.Contains(sensitiveHeaders, lower) && !strings.EqualFold(key, extra) {
+			redacted[key] = value
+			continue
+		}
+		if scheme, _, found := strings.Cut(value, " "); found && strings.HasSuffix(lower, "authorization") {
+			redacted[key] = scheme + " " + redactedValue
+		} else {
+			redacted[key] = redactedValue
+		}
+	}
+	return redacted
+}
+
+// videoPath 获取当前页面的录屏路径
+// Video.Path 重复调用会阻塞到页面关闭，因此只获取一次并缓存结果
+func (r *Runner) videoPath() string {
+	if r.videoResolved || r.page == nil {
+		return r.video
+	}
+	r.videoResolved = true
+	if video := r.page.Video(); video != nil {
+		if path, err := video.Path(); err == nil {
+			r.video = path
+		}
+	}
+	return r.video
+}
@@ -0,0 +1,41 @@
+package runner
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ReportSpec 报告输出配置，对应命令行参数 -report format=path
+type ReportSpec struct {
+	Format string // 报告格式: "junit", "html"
+	Path   string // 输出文件路径
+}
+
+// ParseReportSpec 解析 "format=path" 形式的报告配置
+func ParseReportSpec(value string) (ReportSpec, error) {
+	format, path, ok := strings.Cut(value, "=")
+	format = strings.ToLower(strings.TrimSpace(format))
+	path = strings.TrimSpace(path)
+	if !ok || format == "" || path == "" {
+		return ReportSpec{}, fmt.Errorf("报告参数格式错误: '%s'，应为 format=path，例如 junit=report.xml", value)
+	}
+
+	switch format {
+	case "junit", "html":
+	default:
+		return ReportSpec{}, fmt.Errorf("不支持的报告格式: %s", format)
+	}
+	return ReportSpec{Format: format, Path: path}, nil
+}
+
+// WriteReport 按指定格式输出报告
+func WriteReport(spec ReportSpec, results ...*SuiteResult) error {
+	switch spec.Format {
+	case "junit":
+		return WriteJUnitReport(spec.Path, results...)
+	case "html":
+		return WriteHTMLReport(spec.Path, results...)
+	default:
+		return fmt.Errorf("不支持的报告格式: %s", spec.Format)
+	}
+}
@@ -0,0 +1,257 @@
+package runner
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// htmlReportData 渲染 HTML 报告所需的数据
+type htmlReportData struct {
+	Title       string
+	GeneratedAt string
+	Summary     Summary
+	Duration    time.Duration
+	Suites      []*SuiteResult
+}
+
+// WriteHTMLReport 生成单文件 HTML 报告
+// 失败截图以 base64 内嵌，录屏以相对路径链接，不依赖任何外部 CDN 资源，可离线查看
+func WriteHTMLReport(path string, results ...*SuiteResult) error {
+	data := htmlReportData{
+		Title:       "自动化测试报告",
+		GeneratedAt: time.Now().Format("2006-01-02 15:04:05"),
+		Suites:      results,
+	}
+	for _, suite := range results {
+		summary := suite.Summary()
+		data.Summary.Total += summary.Total
+		data.Summary.Passed += summary.Passed
+		data.Summary.Failed += summary.Failed
+		data.Summary.Skipped += summary.Skipped
+		data.Summary.Flaky += summary.Flaky
+		data.Duration += suite.Duration
+	}
+
+	reportDir := filepath.Dir(path)
+	tmpl, err := template.New("report").Funcs(template.FuncMap{
+		"duration": func(d time.Duration) string {
+			return d.Round(time.Millisecond).String()
+		},
+		"embedImage": embedImage,
+		"relPath": func(file string) string {
+			return relativePath(reportDir, file)
+		},
+		"inc": func(i int) int {
+			return i + 1
+		},
+		"prettyBody": prettyBody,
+		"headers":    formatHeaders,
+		"event":      describeEvent,
+		"reqHeaders": func(h map[string]string) string {
+			header := http.Header{}
+			for k, v := range h {
+				header.Set(k, v)
+			}
+			return formatHeaders(header)
+		},
+	}).Parse(htmlReportTemplate)
+	if err != nil {
+		return fmt.Errorf("解析 HTML 报告模板失败: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return fmt.Errorf("生成 HTML 报告失败: %v", err)
+	}
+
+	if err := os.MkdirAll(reportDir, 0755); err != nil {
+		return fmt.Errorf("创建报告目录失败: %v", err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("写入 HTML 报告失败: %v", err)
+	}
+	return nil
+}
+
+// embedImage 读取图片并转换为 data URI，非图片或读取失败时返回空
+func embedImage(file string) template.URL {
+	var mime string
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".png":
+		mime = "image/png"
+	case ".jpg", ".jpeg":
+		mime = "image/jpeg"
+	default:
+		return ""
+	}
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return ""
+	}
+	return template.URL("data:" + mime + ";base64," + base64.StdEncoding.EncodeToString(content))
+}
+
+// relativePath 将产物路径转换为相对报告文件的路径，便于整体拷贝后仍可打开
+func relativePath(reportDir, file string) string {
+	absFile, err := filepath.Abs(file)
+	if err != nil {
+		return filepath.ToSlash(file)
+	}
+	absDir, err := filepath.Abs(reportDir)
+	if err != nil {
+		return filepath.ToSlash(absFile)
+	}
+	rel, err := filepath.Rel(absDir, absFile)
+	if err != nil {
+		return filepath.ToSlash(absFile)
+	}
+	return filepath.ToSlash(rel)
+}
+
+// prettyBody 如果内容是 JSON 则格式化输出
+func prettyBody(body string) string {
+	var out bytes.Buffer
+	if err := json.Indent(&out, []byte(body), "", "  "); err == nil {
+		return out.String()
+	}
+	return body
+}
+
+// formatHeaders 按名称排序输出请求/响应头
+func formatHeaders(header http.Header) string {
+	names := make([]string, 0, len(header))
+	for name := range header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	lines := make([]string, 0, len(names))
+	for _, name := range names {
+		lines = append(lines, fmt.Sprintf("%s: %s", name, strings.Join(header[name], ", ")))
+	}
+	return strings.Join(lines, "\n")
+}
+
+const htmlReportTemplate = `<!DOCTYPE html>
+<html lang="zh-CN">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", "Microsoft YaHei", sans-serif; margin: 24px; color: #222; background: #f6f7f9; }
+h1 { margin: 0 0 4px; }
+.meta { color: #666; margin-bottom: 16px; }
+.summary span { display: inline-block; margin-right: 12px; padding: 4px 10px; border-radius: 4px; background: #fff; border: 1px solid #ddd; }
+.suite { margin-top: 24px; }
+details.case { background: #fff; border: 1px solid #ddd; border-left: 6px solid #999; border-radius: 4px; margin: 8px 0; padding: 8px 12px; }
+details.case.passed { border-left-color: #2e9d4c; }
+details.case.failed { border-left-color: #d64541; }
+details.case.skipped { border-left-color: #c9a227; }
+summary { cursor: pointer; font-weight: 600; }
+.status { display: inline-block; min-width: 56px; text-align: center; border-radius: 3px; color: #fff; font-size: 12px; padding: 1px 6px; margin-right: 6px; }
+.status.passed { background: #2e9d4c; }
+.status.failed { background: #d64541; }
+.status.skipped { background: #c9a227; }
+.status.flaky { background: #e08a1e; }
+.attempt { border: 1px dashed #e0a060; border-radius: 4px; padding: 6px 10px; margin: 8px 0; background: #fffaf3; }
+.error { color: #d64541; white-space: pre-wrap; margin: 8px 0; }
+table { border-collapse: collapse; width: 100%; margin: 8px 0; font-size: 13px; }
+th, td { border: 1px solid #e2e2e2; padding: 4px 8px; text-align: left; vertical-align: top; }
+th { background: #fafafa; }
+pre { background: #f3f3f3; padding: 8px; overflow-x: auto; white-space: pre-wrap; word-break: break-all; margin: 4px 0; }
+img.shot { max-width: 100%; border: 1px solid #ccc; margin-top: 8px; }
+video { max-width: 100%; margin-top: 8px; }
+.api { border: 1px solid #e2e2e2; border-radius: 4px; padding: 6px 10px; margin: 8px 0; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<div class="meta">生成时间: {{.GeneratedAt}}　总耗时: {{duration .Duration}}</div>
+<div class="summary">
+<span>用例总数: {{.Summary.Total}}</span>
+<span>通过: {{.Summary.Passed}}</span>
+<span>失败: {{.Summary.Failed}}</span>
+<span>重试后通过: {{.Summary.Flaky}}</span>
+<span>跳过: {{.Summary.Skipped}}</span>
+</div>
+{{range .Suites}}
+<div class="suite">
+<h2>{{.Name}}</h2>
+{{range .Cases}}
+<details class="case {{.Status}}"{{if eq .Status "failed"}} open{{end}}>
+<summary><span class="status {{.Status}}">{{.Status}}</span>{{if .Flaky}}<span class="status flaky">flaky</span>{{end}}{{.Name}} <small>({{duration .Duration}})</small></summary>
+{{if .Error}}<div class="error">{{.Error}}</div>{{end}}
+{{range $i, $a := .FailedAttempts}}
+<div class="attempt">
+<div><b>第 {{inc $i}} 次执行失败</b> <small>({{duration $a.Duration}})</small></div>
+<div class="error">{{$a.Error}}</div>
+{{range $a.Artifacts}}{{$img := embedImage .}}{{if $img}}<div><a href="{{relPath .}}">{{.}}</a><br><img class="shot" src="{{$img}}" alt="{{.}}"></div>{{else}}<div><a href="{{relPath .}}">{{.}}</a></div>{{end}}{{end}}
+{{if $a.Trace}}<div>Trace: <a href="{{relPath $a.Trace}}">{{$a.Trace}}</a></div>{{end}}
+</div>
+{{end}}
+{{if .Steps}}
+<table>
+<tr><th>#</th><th>Action</th><th>目标</th><th>耗时</th><th>结果</th></tr>
+{{range .Steps}}
+<tr>
+<td>{{.Index}}</td>
+<td>{{.Action}}</td>
+<td>{{.Target}}</td>
+<td>{{duration .Duration}}</td>
+<td><span class="status {{.Status}}">{{.Status}}</span>{{if .Error}}<div class="error">{{.Error}}</div>{{end}}{{range $i, $e := .FailedAttempts}}<div class="error">重试前第 {{inc $i}} 次失败: {{$e}}</div>{{end}}</td>
+</tr>
+{{end}}
+</table>
+{{end}}
+{{if .Events}}
+<div>页面错误信号</div>
+<table>
+<tr><th>步骤</th><th>时间</th><th>信号</th></tr>
+{{range .Events}}
+<tr>
+<td>{{if .Step}}{{.Step}}{{end}}</td>
+<td>{{.Time.Format "15:04:05.000"}}</td>
+<td>{{if .Fatal}}<span class="status failed">failed</span>{{end}}{{event .}}</td>
+</tr>
+{{end}}
+</table>
+{{end}}
+{{range .API}}
+<div class="api">
+<div><b>{{.Method}}</b> {{.URL}} → {{if .StatusCode}}{{.StatusCode}}{{else}}请求失败{{end}} <small>({{duration .Duration}})</small></div>
+{{if .Error}}<div class="error">{{.Error}}</div>{{end}}
+<div>请求头</div><pre>{{reqHeaders .RequestHeaders}}</pre>
+{{if .RequestBody}}<div>请求体</div><pre>{{.RequestBody}}</pre>{{end}}
+{{if .StatusCode}}<div>响应头</div><pre>{{headers .ResponseHeaders}}</pre>
+<div>响应体</div><pre>{{prettyBody .ResponseBody}}</pre>{{end}}
+</div>
+{{end}}
+{{with .Tool}}
+<div class="api">
+<div><b>$</b> {{.Command}} → 退出码 {{.ExitCode}} <small>({{duration .Duration}})</small></div>
+<div>标准输出</div><pre>{{.Stdout}}</pre>
+{{if .Stderr}}<div>标准错误</div><pre>{{.Stderr}}</pre>{{end}}
+</div>
+{{end}}
+{{range .Artifacts}}
+{{$img := embedImage .}}
+{{if $img}}<div><a href="{{relPath .}}">{{.}}</a><br><img class="shot" src="{{$img}}" alt="{{.}}"></div>{{else}}<div><a href="{{relPath .}}">{{.}}</a></div>{{end}}
+{{end}}
+{{if .Trace}}<div>Trace: <a href="{{relPath .Trace}}">{{.Trace}}</a> <small>(npx playwright show-trace {{.Trace}})</small></div>{{end}}
+{{if .Video}}<div>录屏: <a href="{{relPath .Video}}">{{.Video}}</a><br><video controls preload="none" src="{{relPath .Video}}"></video></div>{{end}}
+</details>
+{{end}}
+</div>
+{{end}}
+</body>
+</html>
+`
@@ -0,0 +1,187 @@
+package runner
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// junitTestSuites JUnit XML 根节点
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Errors    int             `xml:"errors,attr"`
+	Skipped   int             `xml:"skipped,attr"`
+	Time      string          `xml:"time,attr"`
+	Timestamp string          `xml:"timestamp,attr,omitempty"`
+	Cases     []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Skipped   *junitSkipped `xml:"skipped,omitempty"`
+	// 重试前失败的执行（Maven Surefire 格式）：最终通过时为 flakyFailure，最终失败时为 rerunFailure
+	FlakyFailures []junitFailure `xml:"flakyFailure,omitempty"`
+	RerunFailures []junitFailure `xml:"rerunFailure,omitempty"`
+	SystemOut     string         `xml:"system-out,omitempty"`
+	SystemErr     string         `xml:"system-err,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr,omitempty"`
+	Body    string `xml:",chardata"`
+}
+
+type junitSkipped struct {
+	Message string `xml:"message,attr,omitempty"`
+}
+
+// WriteJUnitReport 将套件结果写为 JUnit XML 文件（Jenkins / GitLab 可直接解析）
+func WriteJUnitReport(path string, results ...*SuiteResult) error {
+	root := junitTestSuites{}
+	var totalSeconds float64
+
+	for _, suite := range results {
+		summary := suite.Summary()
+		js := junitTestSuite{
+			Name:     suite.Name,
+			Tests:    summary.Total,
+			Failures: summary.Failed,
+			Skipped:  summary.Skipped,
+			Time:     formatSeconds(suite.Duration.Seconds()),
+		}
+		if !suite.StartTime.IsZero() {
+			js.Timestamp = suite.StartTime.Format("2006-01-02T15:04:05")
+		}
+
+		for _, c := range suite.Cases {
+			js.Cases = append(js.Cases, buildJUnitCase(suite.Name, c))
+		}
+
+		root.Tests += summary.Total
+		root.Failures += summary.Failed
+		root.Skipped += summary.Skipped
+		totalSeconds += suite.Duration.Seconds()
+		root.Suites = append(root.Suites, js)
+	}
+	root.Time = formatSeconds(totalSeconds)
+
+	data, err := xml.MarshalIndent(root, "", "  ")
+	if err != nil {
+		return fmt.Errorf("生成 JUnit 报告失败: %v", err)
+	}
+
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("创建报告目录失败: %v", err)
+		}
+	}
+	content := append([]byte(xml.Header), data...)
+	if err := os.WriteFile(path, append(content, '\n'), 0644); err != nil {
+		return fmt.Errorf("写入 JUnit 报告失败: %v", err)
+	}
+	return nil
+}
+
+// buildJUnitCase 将单个用例结果转换为 <testcase> 节点
+func buildJUnitCase(suiteName string, c *CaseResult) junitTestCase {
+	jc := junitTestCase{
+		Name:      c.Name,
+		Classname: suiteName,
+		Time:      formatSeconds(c.Duration.Seconds()),
+	}
+
+	switch c.Status {
+	case StatusFailed:
+		failure := buildJUnitFailure(c)
+		jc.Failure = &failure
+	case StatusSkipped:
+		jc.Skipped = &junitSkipped{Message: c.Error}
+	}
+
+	var retried []junitFailure
+	for _, attempt := range c.FailedAttempts {
+		retried = append(retried, buildJUnitFailure(attempt))
+	}
+	// 步骤级重试在用例内部完成，同样记录为重试前的失败
+	for _, step := range c.Steps {
+		for _, stepErr := range step.FailedAttempts {
+			retried = append(retried, junitFailure{
+				Message: fmt.Sprintf("步骤 [%d] %s 执行失败（已重试）", step.Index, step.Action),
+				Type:    step.Action,
+				Body:    fmt.Sprintf("step: %d\naction: %s\nerror: %s", step.Index, step.Action, stepErr),
+			})
+		}
+	}
+	if c.Status == StatusFailed {
+		jc.RerunFailures = retried
+	} else {
+		jc.FlakyFailures = retried
+	}
+
+	// 截图、trace 等产物以 Jenkins Attachments 插件识别的格式写入 system-out
+	// 重试前失败的截图与 trace 一并附上
+	var artifacts []string
+	attempts := append([]*CaseResult{}, c.FailedAttempts...)
+	for _, attempt := range append(attempts, c) {
+		artifacts = append(artifacts, attempt.Artifacts...)
+		if attempt.Trace != "" {
+			artifacts = append(artifacts, attempt.Trace)
+		}
+	}
+	if len(artifacts) > 0 {
+		lines := make([]string, 0, len(artifacts))
+		for _, artifact := range artifacts {
+			if abs, err := filepath.Abs(artifact); err == nil {
+				artifact = abs
+			}
+			lines = append(lines, fmt.Sprintf("[[ATTACHMENT|%s]]", artifact))
+		}
+		jc.SystemOut = strings.Join(lines, "\n")
+	}
+
+	// 页面错误信号写入 system-err
+	if len(c.Events) > 0 {
+		lines := make([]string, 0, len(c.Events))
+		for _, event := range c.Events {
+			lines = append(lines, fmt.Sprintf("[step %d] %s", event.Step, describeEvent(event)))
+		}
+		jc.SystemErr = strings.Join(lines, "\n")
+	}
+	return jc
+}
+
+// buildJUnitFailure 将失败的用例结果转换为 <failure> 节点内容
+func buildJUnitFailure(c *CaseResult) junitFailure {
+	message := c.Error
+	body := c.Error
+	if c.FailedStep > 0 {
+		message = fmt.Sprintf("步骤 [%d] %s 执行失败", c.FailedStep, c.FailedAction)
+		body = fmt.Sprintf("step: %d\naction: %s\nerror: %s", c.FailedStep, c.FailedAction, c.Error)
+	}
+	return junitFailure{
+		Message: message,
+		Type:    c.FailedAction,
+		Body:    body,
+	}
+}
+
+func formatSeconds(seconds float64) string {
+	return fmt.Sprintf("%.3f", seconds)
+}
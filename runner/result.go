@@ -0,0 +1,181 @@
+package runner
+
+import (
+	toolsTemplate "autotest/tools-template"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// CaseStatus 用例执行状态
+type CaseStatus string
+
+const (
+	StatusPassed  CaseStatus = "passed"  // 通过
+	StatusFailed  CaseStatus = "failed"  // 失败
+	StatusSkipped CaseStatus = "skipped" // 跳过（skip: true 或 fail_fast 后未执行）
+)
+
+// CaseResult 单个用例的执行结果
+type CaseResult struct {
+	Name      string        // 用例名称
+	Status    CaseStatus    // 执行状态
+	Error     string        // 失败原因（通过时为空）
+	StartTime time.Time     // 开始时间
+	Duration  time.Duration // 执行耗时
+	Artifacts []string      // 产物路径（错误截图等）
+	Video     string        // 浏览器录屏路径（仅 UI 用例）
+	Trace     string        // Playwright trace 文件路径（仅 UI 用例，按 trace 配置保存）
+
+	FailedStep   int    // 失败步骤序号（从 1 开始，0 表示无）
+	FailedAction string // 失败步骤的 action
+
+	Steps  []*StepResult             // UI 步骤执行记录
+	Events []*BrowserEvent           // UI 用例执行期间的页面错误信号
+	API    []*APIExchange            // API 请求/响应记录
+	Tool   *toolsTemplate.ToolResult // 系统工具命令执行结果
+
+	FailedAttempts []*CaseResult // 用例级重试前失败的各次执行结果（按执行顺序）
+}
+
+// Flaky 是否为不稳定用例：最终通过，但此前有失败的执行或步骤重试
+func (c *CaseResult) Flaky() bool {
+	if c.Status != StatusPassed {
+		return false
+	}
+	if len(c.FailedAttempts) > 0 {
+		return true
+	}
+	for _, step := range c.Steps {
+		if len(step.FailedAttempts) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// StepResult 单个 UI 步骤的执行记录
+type StepResult struct {
+	Index      int           // 步骤序号（从 1 开始）
+	Action     string        // 步骤 action
+	Target     string        // 操作对象描述（选择器、URL、菜单路径等）
+	Status     CaseStatus    // 执行状态
+	Error      string        // 失败原因
+	StartTime  time.Time     // 开始时间
+	Duration   time.Duration // 执行耗时
+	Screenshot string        // 失败截图路径
+
+	FailedAttempts []string // 步骤级重试前失败的各次错误信息（按执行顺序）
+}
+
+// 页面错误信号类型
+const (
+	EventConsole       = "console"       // console.error / console.warn 输出
+	EventPageError     = "pageerror"     // 未捕获的 JS 异常
+	EventRequestFailed = "requestfailed" // 网络层失败的请求
+	EventResponse      = "response"      // 4xx / 5xx 响应
+)
+
+// BrowserEvent 用例执行期间页面产生的错误信号
+type BrowserEvent struct {
+	Type    string    // 信号类型
+	Level   string    // console 消息级别（error / warning）
+	Message string    // 消息内容、异常信息或请求失败原因
+	Method  string    // 请求方法
+	URL     string    // 请求地址，console 为消息来源
+	Status  int       // 响应状态码
+	Step    int       // 发生时正在执行的步骤序号
+	Time    time.Time // 发生时间
+	Fatal   bool      // 是否导致用例失败
+}
+
+// APIExchange 一次 API 调用的请求与响应
+type APIExchange struct {
+	Method          string            // 请求方法
+	URL             string            // 请求地址
+	RequestHeaders  map[string]string // 请求头
+	RequestBody     string            // 请求体（JSON / 表单 / 原始文本，multipart 文件以 field=@path 表示）
+	StatusCode      int               // 响应状态码（请求失败时为 0）
+	ResponseHeaders http.Header       // 响应头
+	ResponseBody    string            // 原始响应体
+	Duration        time.Duration     // 请求耗时
+	Error           string            // 请求发送失败的原因
+}
+
+// StepError 步骤执行错误，记录失败步骤的位置
+type StepError struct {
+	Index  int    // 步骤序号（从 1 开始）
+	Action string // 步骤 action
+	Err    error  // 原始错误
+}
+
+func (e *StepError) Error() string {
+	return fmt.Sprintf("步骤 [%d] %s 执行失败: %v", e.Index, e.Action, e.Err)
+}
+
+func (e *StepError) Unwrap() error {
+	return e.Err
+}
+
+// SuiteResult 测试套件的执行结果
+type SuiteResult struct {
+	Name      string        // 套件名称（通常为测试文件路径）
+	StartTime time.Time     // 开始时间
+	Duration  time.Duration // 总耗时
+	Cases     []*CaseResult // 按用例定义顺序排列的结果
+}
+
+// Summary 执行结果统计
+type Summary struct {
+	Total   int
+	Passed  int
+	Failed  int
+	Skipped int
+	Flaky   int // 重试后通过的用例数（已计入 Passed）
+}
+
+// Summary 统计套件中各状态的用例数
+func (s *SuiteResult) Summary() Summary {
+	summary := Summary{Total: len(s.Cases)}
+	for _, c := range s.Cases {
+		switch c.Status {
+		case StatusPassed:
+			summary.Passed++
+			if c.Flaky() {
+				summary.Flaky++
+			}
+		case StatusFailed:
+			summary.Failed++
+		case StatusSkipped:
+			summary.Skipped++
+		}
+	}
+	return summary
+}
+
+// HasFailures 是否存在失败的用例
+func (s *SuiteResult) HasFailures() bool {
+	return s.Summary().Failed > 0
+}
+
+// PrintSummary 打印执行汇总
+func (s *SuiteResult) PrintSummary() {
+	summary := s.Summary()
+	fmt.Println("==================== 执行汇总 ====================")
+	for _, c := range s.Cases {
+		switch c.Status {
+		case StatusPassed:
+			if c.Flaky() {
+				fmt.Printf("  ⚠️  %s (%s): 重试后通过\n", c.Name, c.Duration.Round(time.Millisecond))
+				continue
+			}
+			fmt.Printf("  ✅ %s (%s)\n", c.Name, c.Duration.Round(time.Millisecond))
+		case StatusFailed:
+			fmt.Printf("  ❌ %s (%s): %s\n", c.Name, c.Duration.Round(time.Millisecond), c.Error)
+		case StatusSkipped:
+			fmt.Printf("  ⏭️  %s (跳过)\n", c.Name)
+		}
+	}
+	fmt.Printf("共 %d 个用例: 通过 %d (其中重试后通过 %d), 失败 %d, 跳过 %d, 耗时 %s\n",
+		summary.Total, summary.Passed, summary.Flaky, summary.Failed, summary.Skipped, s.Duration.Round(time.Millisecond))
+}
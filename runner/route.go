@@ -0,0 +1,516 @@
+package runner
+
+import (
+	browseTemplate "autotest/browse-template"
+	"autotest/browse-template/utils"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// routeMocks 当前用例通过 route_mock 注册的请求拦截，用例结束时全部移除，不会影响后续用例
+// 拦截处理在 Playwright 的回调中执行，处理失败时记录错误，在当前步骤结束时报告
+type routeMocks struct {
+	mu      sync.Mutex
+	context playwright.BrowserContext
+	entries []routeEntry
+	err     error
+}
+
+type routeEntry struct {
+	pattern string
+	re      *regexp.Regexp
+}
+
+// fail 记录拦截处理中出现的第一个错误
+func (m *routeMocks) fail(err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.err == nil {
+		m.err = err
+	}
+}
+
+// takeError 取出并清除已记录的错误
+func (m *routeMocks) takeError() error {
+	if m == nil {
+		return nil
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	err := m.err
+	m.err = nil
+	return err
+}
+
+// clear 移除 url 规则相同的拦截，pattern 为空时移除全部，返回移除的数量
+func (m *routeMocks) clear(pattern string) (int, error) {
+	if m == nil {
+		return 0, nil
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var remaining []routeEntry
+	removed := 0
+	var errs []error
+	for _, entry := range m.entries {
+		if pattern != "" && entry.pattern != pattern {
+			remaining = append(remaining, entry)
+			continue
+		}
+		removed++
+		if err := m.context.Unroute(entry.re); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	m.entries = remaining
+	if len(errs) > 0 {
+		return removed, fmt.Errorf("移除请求拦截失败: %v", errors.Join(errs...))
+	}
+	return removed, nil
+}
+
+// isRouteAction 是否为请求拦截步骤
+func isRouteAction(action string) bool {
+	return strings.HasPrefix(action, "route_")
+}
+
+// startRouteMocks 为当前 UI 用例准备请求拦截，拦截注册在页面所属的浏览器上下文上
+func (r *Runner) startRouteMocks() {
+	r.routes = &routeMocks{context: r.page.Context()}
+}
+
+// stopRouteMocks 移除当前用例注册的全部请求拦截
+func (r *Runner) stopRouteMocks() {
+	if _, err := r.routes.clear(""); err != nil {
+		fmt.Fprintf(r.out, "  ⚠️  %v\n", err)
+	}
+	r.routes = nil
+}
+
+// handleRouteMock 拦截 url 匹配的请求，返回模拟响应、中止、延迟或修改真实响应
+func (r *Runner) handleRouteMock(step browseTemplate.TestStep) error {
+	if step.URL == "" {
+		return errors.New("route_mock action 需要提供 url")
+	}
+	if step.Mock == nil {
+		return errors.New("route_mock action 需要提供 mock")
+	}
+	mock := *step.Mock
+	if err := validateRouteMock(mock); err != nil {
+		return err
+	}
+	re, err := utils.CompileURLPattern(step.URL)
+	if err != nil {
+		return err
+	}
+
+	// 字符串原样返回，对象或数组按 JSON 返回
+	var body []byte
+	contentType := ""
+	if len(mock.Body) > 0 {
+		var text string
+		if err := json.Unmarshal(mock.Body, &text); err == nil {
+			body = []byte(text)
+		} else {
+			body = mock.Body
+			contentType = "application/json"
+		}
+	}
+
+	pattern := step.URL
+	mocks := r.routes
+	handler := func(route playwright.Route) {
+		if mock.Method != "" && !strings.EqualFold(route.Request().Method(), mock.Method) {
+			_ = route.Fallback()
+			return
+		}
+		if mock.DelayMs > 0 {
+			time.Sleep(time.Duration(mock.DelayMs) * time.Millisecond)
+		}
+		if err := fulfillRouteMock(route, mock, body, contentType); err != nil {
+			mocks.fail(fmt.Errorf("请求拦截 '%s' 处理失败 (%s): %v", pattern, route.Request().URL(), err))
+			_ = route.Fallback()
+		}
+	}
+
+	var times []int
+	if mock.Times > 0 {
+		times = append(times, mock.Times)
+	}
+	if err := mocks.context.Route(re, handler, times...); err != nil {
+		return fmt.Errorf("注册请求拦截失败: %v", err)
+	}
+	mocks.mu.Lock()
+	mocks.entries = append(mocks.entries, routeEntry{pattern: pattern, re: re})
+	mocks.mu.Unlock()
+	fmt.Fprintf(r.out, "    🔀 拦截请求 %s -> %s\n", pattern, describeRouteMock(mock))
+	return nil
+}
+
+// handleRouteClear 移除 url 规则相同的请求拦截，未提供 url 时移除当前用例注册的全部拦截
+func (r *Runner) handleRouteClear(step browseTemplate.TestStep) error {
+	removed, err := r.routes.clear(step.URL)
+	if err != nil {
+		return err
+	}
+	if step.URL != "" && removed == 0 {
+		return fmt.Errorf("没有 url 为 '%s' 的请求拦截", step.URL)
+	}
+	fmt.Fprintf(r.out, "    🔀 已移除 %d 个请求拦截\n", removed)
+	return nil
+}
+
+// resolveMockFiles 将 route_mock 步骤中 mock.body_file 的相对路径解析为基于测试文件所在目录的路径
+// 以占位符开头的路径（如 {data_dir}/list.json）在运行时替换，保持原样
+// 数据驱动展开的用例共享步骤，因此复制步骤后再修改
+func resolveMockFiles(testCase TestCase, baseDir string) TestCase {
+	var steps []browseTemplate.TestStep
+	for i, step := range testCase.Steps {
+		if step.Mock == nil || step.Mock.BodyFile == "" || filepath.IsAbs(step.Mock.BodyFile) || strings.HasPrefix(step.Mock.BodyFile, "{") {
+			continue
+		}
+		if steps == nil {
+			steps = append([]browseTemplate.TestStep(nil), testCase.Steps...)
+		}
+		mock := *step.Mock
+		mock.BodyFile = filepath.Join(baseDir, mock.BodyFile)
+		steps[i].Mock = &mock
+	}
+	if steps != nil {
+		testCase.Steps = steps
+	}
+	return testCase
+}
+
+// validateRouteMock 在注册前检查配置，避免在拦截回调中才发现错误
+func validateRouteMock(mock browseTemplate.RouteMockConfig) error {
+	hasResponse := mock.Status != 0 || len(mock.Headers) > 0 || len(mock.Body) > 0 || mock.BodyFile != ""
+	switch {
+	case mock.Abort != "" && (hasResponse || len(mock.Patch) > 0):
+		return errors.New("mock.abort 不能与响应配置或 patch 同时使用")
+	case len(mock.Body) > 0 && mock.BodyFile != "":
+		return errors.New("mock.body 与 mock.body_file 只能配置一个")
+	case len(mock.Patch) > 0 && (len(mock.Body) > 0 || mock.BodyFile != ""):
+		return errors.New("mock.patch 修改真实响应，不能与 body / body_file 同时使用")
+	case mock.Abort == "" && !hasResponse && len(mock.Patch) == 0 && mock.DelayMs <= 0:
+		return errors.New("mock 需要配置 status、body、body_file、abort、patch 或 delay_ms")
+	}
+	if mock.BodyFile != "" {
+		if _, err := os.Stat(mock.BodyFile); err != nil {
+			return fmt.Errorf("mock.body_file 不存在: %v", err)
+		}
+	}
+	for i, op := range mock.Patch {
+		switch op.Op {
+		case "add", "replace", "test":
+			if len(op.Value) == 0 {
+				return fmt.Errorf("patch 第 %d 个操作 (%s) 缺少 value", i+1, op.Op)
+			}
+		case "remove":
+		case "move", "copy":
+			if op.From == "" {
+				return fmt.Errorf("patch 第 %d 个操作 (%s) 缺少 from", i+1, op.Op)
+			}
+		default:
+			return fmt.Errorf("patch 第 %d 个操作的 op 无效: %s", i+1, op.Op)
+		}
+	}
+	return nil
+}
+
+// fulfillRouteMock 按配置处理一次拦截到的请求
+func fulfillRouteMock(route playwright.Route, mock browseTemplate.RouteMockConfig, body []byte, contentType string) error {
+	switch {
+	case mock.Abort != "":
+		return route.Abort(mock.Abort)
+	case len(mock.Patch) > 0:
+		return fulfillPatchedResponse(route, mock)
+	case mock.Status == 0 && len(mock.Headers) == 0 && len(mock.Body) == 0 && mock.BodyFile == "":
+		// 只配置了延迟
+		return route.Fallback()
+	}
+
+	status := mock.Status
+	if status == 0 {
+		status = 200
+	}
+	options := playwright.RouteFulfillOptions{Status: playwright.Int(status), Headers: mock.Headers}
+	if mock.BodyFile != "" {
+		options.Path = playwright.String(mock.BodyFile)
+	} else if body != nil {
+		options.Body = body
+	}
+	if contentType != "" && !hasHeaderFold(mock.Headers, "Content-Type") {
+		options.ContentType = playwright.String(contentType)
+	}
+	return route.Fulfill(options)
+}
+
+// fulfillPatchedResponse 请求真实接口，对响应 JSON 执行 patch 后返回，status / headers 可同时覆盖
+func fulfillPatchedResponse(route playwright.Route, mock browseTemplate.RouteMockConfig) error {
+	resp, err := route.Fetch()
+	if err != nil {
+		return fmt.Errorf("请求真实接口失败: %v", err)
+	}
+	data, err := resp.Body()
+	if err != nil {
+		return fmt.Errorf("读取真实响应失败: %v", err)
+	}
+	var doc interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("真实响应不是 JSON: %v", err)
+	}
+	doc, err = applyJSONPatch(doc, mock.Patch)
+	if err != nil {
+		return err
+	}
+	patched, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+
+	// 响应体已被解码和修改，原有的长度与压缩编码不再适用
+	headers := make(map[string]string)
+	for key, value := range resp.Headers() {
+		if !strings.EqualFold(key, "Content-Length") && !strings.EqualFold(key, "Content-Encoding") {
+			headers[strings.ToLower(key)] = value
+		}
+	}
+	for key, value := range mock.Headers {
+		headers[strings.ToLower(key)] = value
+	}
+	status := resp.Status()
+	if mock.Status != 0 {
+		status = mock.Status
+	}
+	return route.Fulfill(playwright.RouteFulfillOptions{
+		Response: resp,
+		Status:   playwright.Int(status),
+		Headers:  headers,
+		Body:     patched,
+	})
+}
+
+// describeRouteMock 生成拦截配置的简短描述，用于日志
+func describeRouteMock(mock browseTemplate.RouteMockConfig) string {
+	var parts []string
+	if mock.Method != "" {
+		parts = append(parts, strings.ToUpper(mock.Method))
+	}
+	switch {
+	case mock.Abort != "":
+		parts = append(parts, "abort "+mock.Abort)
+	case len(mock.Patch) > 0:
+		parts = append(parts, fmt.Sprintf("patch %d 项", len(mock.Patch)))
+	case mock.BodyFile != "":
+		parts = append(parts, "file "+mock.BodyFile)
+	}
+	if mock.Status != 0 {
+		parts = append(parts, fmt.Sprintf("status %d", mock.Status))
+	}
+	if mock.DelayMs > 0 {
+		parts = append(parts, fmt.Sprintf("延迟 %dms", mock.DelayMs))
+	}
+	if mock.Times > 0 {
+		parts = append(parts, fmt.Sprintf("%d 次", mock.Times))
+	}
+	if len(parts) == 0 {
+		parts = append(parts, "status 200")
+	}
+	return strings.Join(parts, ", ")
+}
+
+func hasHeaderFold(headers map[string]string, name string) bool {
+	for key := range headers {
+		if strings.EqualFold(key, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// applyJSONPatch 对 JSON 文档依次执行 JSON Patch (RFC 6902) 操作
+func applyJSONPatch(doc interface{}, ops []browseTemplate.JSONPatchOp) (interface{}, error) {
+	for i, op := range ops {
+		var err error
+		switch op.Op {
+		case "add", "replace":
+			var value interface{}
+			if err = json.Unmarshal(op.Value, &value); err == nil {
+				doc, err = patchPointer(doc, op.Path, op.Op, value)
+			}
+		case "remove":
+			doc, err = patchPointer(doc, op.Path, "remove", nil)
+		case "test":
+			var value, current interface{}
+			if err = json.Unmarshal(op.Value, &value); err == nil {
+				if current, err = getPointer(doc, op.Path); err == nil && !reflect.DeepEqual(current, value) {
+					err = fmt.Errorf("值为 %v，期望 %v", current, value)
+				}
+			}
+		case "move", "copy":
+			var value interface{}
+			if value, err = getPointer(doc, op.From); err != nil {
+				break
+			}
+			if op.Op == "move" {
+				if doc, err = patchPointer(doc, op.From, "remove", nil); err != nil {
+					break
+				}
+			} else {
+				// 复制一份，避免两个位置共享同一个对象
+				copied, _ := json.Marshal(value)
+				_ = json.Unmarshal(copied, &value)
+			}
+			doc, err = patchPointer(doc, op.Path, "add", value)
+		default:
+			err = fmt.Errorf("不支持的操作")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("patch 第 %d 个操作 (%s %s) 失败: %v", i+1, op.Op, op.Path, err)
+		}
+	}
+	return doc, nil
+}
+
+// parsePointer 解析 JSON Pointer，"" 表示整个文档
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("JSON Pointer 必须以 / 开头: %s", pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+// getPointer 读取 JSON Pointer 指向的值
+func getPointer(doc interface{}, pointer string) (interface{}, error) {
+	tokens, err := parsePointer(pointer)
+	if err != nil {
+		return nil, err
+	}
+	node := doc
+	for _, token := range tokens {
+		switch n := node.(type) {
+		case map[string]interface{}:
+			value, ok := n[token]
+			if !ok {
+				return nil, fmt.Errorf("路径不存在: %s", pointer)
+			}
+			node = value
+		case []interface{}:
+			index, err := arrayIndex(token, len(n))
+			if err != nil {
+				return nil, err
+			}
+			node = n[index]
+		default:
+			return nil, fmt.Errorf("路径不存在: %s", pointer)
+		}
+	}
+	return node, nil
+}
+
+// patchPointer 在 JSON Pointer 指向的位置执行 add / replace / remove，返回更新后的文档
+func patchPointer(doc interface{}, pointer, op string, value interface{}) (interface{}, error) {
+	tokens, err := parsePointer(pointer)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		if op == "remove" {
+			return nil, nil
+		}
+		return value, nil
+	}
+	return patchNode(doc, tokens, op, value, pointer)
+}
+
+func patchNode(node interface{}, tokens []string, op string, value interface{}, pointer string) (interface{}, error) {
+	token := tokens[0]
+	last := len(tokens) == 1
+	switch n := node.(type) {
+	case map[string]interface{}:
+		current, exists := n[token]
+		if last {
+			switch {
+			case op == "add":
+				n[token] = value
+			case !exists:
+				return nil, fmt.Errorf("路径不存在: %s", pointer)
+			case op == "replace":
+				n[token] = value
+			default:
+				delete(n, token)
+			}
+			return n, nil
+		}
+		if !exists {
+			return nil, fmt.Errorf("路径不存在: %s", pointer)
+		}
+		updated, err := patchNode(current, tokens[1:], op, value, pointer)
+		if err != nil {
+			return nil, err
+		}
+		n[token] = updated
+		return n, nil
+	case []interface{}:
+		if last && op == "add" {
+			index := len(n)
+			if token != "-" {
+				var err error
+				if index, err = arrayIndex(token, len(n)+1); err != nil {
+					return nil, err
+				}
+			}
+			n = append(n, nil)
+			copy(n[index+1:], n[index:])
+			n[index] = value
+			return n, nil
+		}
+		index, err := arrayIndex(token, len(n))
+		if err != nil {
+			return nil, err
+		}
+		if last {
+			if op == "replace" {
+				n[index] = value
+				return n, nil
+			}
+			return append(n[:index], n[index+1:]...), nil
+		}
+		updated, err := patchNode(n[index], tokens[1:], op, value, pointer)
+		if err != nil {
+			return nil, err
+		}
+		n[index] = updated
+		return n, nil
+	default:
+		return nil, fmt.Errorf("路径不存在: %s", pointer)
+	}
+}
+
+// arrayIndex 解析数组下标，有效范围为 [0, size)
+func arrayIndex(token string, size int) (int, error) {
+	index, err := strconv.Atoi(token)
+	if err != nil || index < 0 || index >= size || (len(token) > 1 && token[0] == '0') {
+		return 0, fmt.Errorf("数组下标无效: %s", token)
+	}
+	return index, nil
+}
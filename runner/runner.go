@@ -4,10 +4,13 @@ import (
 	apisTemplate "autotest/apis-template"
 	browseTemplate "autotest/browse-template"
 	"autotest/browse-template/utils"
+	toolsTemplate "autotest/tools-template"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -17,11 +20,25 @@ import (
 // TestCase 测试用例结构
 type TestCase struct {
 	Name string `json:"name"`
+	Skip bool   `json:"skip,omitempty"` // 为 true 时跳过该用例
+	// 并行执行控制：serial 为 true 的用例单独执行，不与其它用例并发；相同 group 的用例在同一 worker 中按顺序执行
+	Serial bool   `json:"serial,omitempty"`
+	Group  string `json:"group,omitempty"`
+	// 失败后整体重试的次数，未设置时使用配置文件中的 retries
+	Retries *int `json:"retries,omitempty"`
+	// 数据驱动：每行数据展开为一个用例，列值通过 {列名} 占位符引用
+	Data     []json.RawMessage `json:"data,omitempty"`      // 内联数据行（JSON 对象）
+	DataFile string            `json:"data_file,omitempty"` // 数据文件（.csv 首行为列名，.json 为对象数组），相对于测试文件所在目录
+	// 登录状态复用（仅 UI 用例）：保存当前上下文的登录状态，或从已保存的状态创建上下文
+	SaveStorageState string `json:"save_storage_state,omitempty"`
+	UseStorageState  string `json:"use_storage_state,omitempty"`
 	// UI 测试字段
 	Steps []browseTemplate.TestStep `json:"steps,omitempty"`
 	// APi 测试字段（可选，留空表示纯 UI 测试）
 	APIConfig *apisTemplate.TestCaseConfig `json:"api_config,omitempty"`
 	APIExpect *apisTemplate.ExpectConfig   `json:"expect,omitempty"`
+	// System Tool 测试字段（可选）
+	ToolConfig *toolsTemplate.TestCaseConfig `json:"tool_config,omitempty"`
 }
 
 // TestSuite 测试套件（支持多个用例）
@@ -29,159 +46,588 @@ type TestSuite []TestCase
 
 // Runner 测试运行器
 type Runner struct {
-	page         playwright.Page
-	apiTemplates apisTemplate.APITemplates
+	page            playwright.Page
+	context         playwright.BrowserContext // 当前用例的浏览器上下文（仅独立上下文模式）
+	newContext      ContextFactory            // 为每个 UI 用例创建独立上下文，为 nil 时使用共享页面
+	keepContextOpen bool                      // 用例结束后保留浏览器上下文
+	out             io.Writer                 // 日志输出（并行执行时为每个用例的缓冲区）
+	parallel        int                       // 并行 worker 数量，<= 1 表示顺序执行
+	apiTemplates    apisTemplate.APITemplates
+	toolTemplates   toolsTemplate.ToolTemplates
+	vars            *Variables                  // 运行期变量（save_response 提取的值），在整个运行过程中共享
+	failFast        bool                        // 为 true 时遇到第一个失败用例即停止，剩余用例标记为跳过
+	config          *browseTemplate.Config      // 浏览器配置（等待超时、自动等待）
+	responses       *responseLog                // 当前 UI 用例收到的响应记录
+	routes          *routeMocks                 // 当前 UI 用例注册的请求拦截
+	pages           *pageTracker                // 当前 UI 用例中打开的页面
+	apiClient       *apisTemplate.Client        // API 客户端（证书、代理、超时等配置）
+	session         *apisTemplate.Client        // 当前用例的 API 会话，同一用例内的请求共享 Cookie
+	auth            *apisTemplate.Authenticator // API 认证配置与令牌缓存，所有用例共享
+	current         *CaseResult                 // 当前执行中的用例结果，UI 步骤中的 API 调用记录到其中
+	video           string                      // 当前页面的录屏路径（首次获取后缓存）
+	videoResolved   bool                        // 是否已获取过录屏路径
+	tracing         bool                        // 当前 UI 用例是否正在录制 trace
 }
 
 // NewRunner 创建新的测试运行器
+// page 为所有 UI 用例共享的页面；调用 SetContextFactory 后改为每个 UI 用例使用独立上下文，此时 page 可传 nil
 func NewRunner(page playwright.Page, apiTemplates apisTemplate.APITemplates) *Runner {
 	return &Runner{
 		page:         page,
 		apiTemplates: apiTemplates,
+		vars:         NewVariables(),
+		out:          os.Stdout,
+		config:       browseTemplate.DefaultConfig(),
+		apiClient:    defaultAPIClient(),
+		auth:         apisTemplate.NewAuthenticator(nil),
 	}
 }
 
-// RunTestCase 执行单个测试用例
-func (r *Runner) RunTestCase(testCase TestCase) error {
-	fmt.Printf("📋 开始执行用例: %s\n", testCase.Name)
+// defaultAPIClient 使用默认配置创建 API 客户端
+func defaultAPIClient() *apisTemplate.Client {
+	client, err := apisTemplate.NewClient(apisTemplate.ClientConfig{})
+	if err != nil {
+		panic(fmt.Sprintf("创建默认 API 客户端失败: %v", err))
+	}
+	return client
+}
+
+// SetAuthProfiles 设置 API 模板通过 auth 引用的认证配置
+func (r *Runner) SetAuthProfiles(profiles apisTemplate.AuthProfiles) {
+	r.auth = apisTemplate.NewAuthenticator(profiles)
+}
+
+// SetAPIClient 设置 API 用例使用的客户端，每个用例在其基础上创建独立的 Cookie 会话
+func (r *Runner) SetAPIClient(client *apisTemplate.Client) {
+	r.apiClient = client
+}
+
+// Variables 返回运行期变量存储
+func (r *Runner) Variables() *Variables {
+	return r.vars
+}
+
+// SetToolTemplates 设置系统工具模板
+func (r *Runner) SetToolTemplates(toolTemplates toolsTemplate.ToolTemplates) {
+	r.toolTemplates = toolTemplates
+}
+
+// SetFailFast 设置是否在第一个失败用例后停止执行
+func (r *Runner) SetFailFast(failFast bool) {
+	r.failFast = failFast
+}
+
+// RunTestCase 执行单个测试用例，返回执行结果
+// 失败时按重试次数重新执行，最终结果中保留此前失败的各次执行记录
+func (r *Runner) RunTestCase(testCase TestCase) *CaseResult {
+	if testCase.Skip {
+		fmt.Fprintf(r.out, "⏭️  跳过用例: %s\n", testCase.Name)
+		return &CaseResult{
+			Name:      testCase.Name,
+			Status:    StatusSkipped,
+			StartTime: time.Now(),
+		}
+	}
+
+	retries := r.config.Retries
+	if testCase.Retries != nil {
+		retries = *testCase.Retries
+	}
+
+	var failedAttempts []*CaseResult
+	for attempt := 0; ; attempt++ {
+		result := r.runAttempt(testCase)
+		if result.Status == StatusPassed || attempt >= retries {
+			if len(failedAttempts) > 0 {
+				result.FailedAttempts = failedAttempts
+				// 耗时包含所有重试
+				result.Duration += result.StartTime.Sub(failedAttempts[0].StartTime)
+				result.StartTime = failedAttempts[0].StartTime
+			}
+			return result
+		}
+		failedAttempts = append(failedAttempts, result)
+		fmt.Fprintf(r.out, "🔁 用例重试 (%d/%d): %s\n", attempt+1, retries, testCase.Name)
+	}
+}
+
+// runAttempt 执行一次测试用例
+func (r *Runner) runAttempt(testCase TestCase) *CaseResult {
+	result := &CaseResult{
+		Name:      testCase.Name,
+		StartTime: time.Now(),
+	}
 
+	fmt.Fprintf(r.out, "📋 开始执行用例: %s\n", testCase.Name)
+	r.session = r.apiClient.NewSession()
+	r.current = result
+	defer func() { r.current = nil }()
+	err := r.executeTestCase(testCase, result)
+	result.Duration = time.Since(result.StartTime)
+	if err != nil {
+		fmt.Fprintf(r.out, "❌ 用例执行失败: %s: %v\n", testCase.Name, err)
+		result.Status = StatusFailed
+		result.Error = err.Error()
+		var stepErr *StepError
+		if errors.As(err, &stepErr) {
+			result.FailedStep = stepErr.Index
+			result.FailedAction = stepErr.Action
+		}
+		return result
+	}
+	result.Status = StatusPassed
+	return result
+}
+
+// executeTestCase 根据用例类型分发执行
+func (r *Runner) executeTestCase(testCase TestCase, result *CaseResult) error {
 	// 分支 1: 如果有 Steps，执行 UI 测试
 	if len(testCase.Steps) > 0 {
-		return r.runUISteps(testCase)
+		if err := r.openContext(testCase); err != nil {
+			return err
+		}
+		defer r.closeContext()
+
+		r.startTrace(testCase)
+		err := r.runUISteps(testCase, result)
+		if err == nil && testCase.SaveStorageState != "" {
+			err = r.saveStorageState(testCase.SaveStorageState)
+		}
+		// trace 需在上下文关闭前保存
+		result.Trace = r.stopTrace(testCase, err != nil)
+		result.Video = r.videoPath()
+		return err
 	}
 
 	// 分支 2: 如果有 APIConfig，执行 API 测试
 	if testCase.APIConfig != nil {
-		return r.runAPITest(testCase)
+		// API 用例视为只有一个步骤，便于报告中统一展示失败位置
+		if err := r.runAPITest(testCase, result); err != nil {
+			return &StepError{Index: 1, Action: "api:" + testCase.APIConfig.Template, Err: err}
+		}
+		return nil
 	}
 
-	// TODO: 分支 3: System Tool 测试
+	// 分支 3: 如果有 ToolConfig，执行 System Tool 测试
+	if testCase.ToolConfig != nil {
+		if err := r.runToolTest(testCase, result); err != nil {
+			return &StepError{Index: 1, Action: "tool:" + testCase.ToolConfig.Template, Err: err}
+		}
+		return nil
+	}
 
-	return fmt.Errorf("无效的测试用例: 没有 steps 或 api_config")
+	return fmt.Errorf("无效的测试用例: 没有 steps、api_config 或 tool_config")
 }
 
-func (r *Runner) runUISteps(testCase TestCase) error {
+func (r *Runner) runUISteps(testCase TestCase, result *CaseResult) error {
+	r.startPages()
+	defer r.stopPages()
+	r.responses = watchResponses(r.page.Context())
+	defer r.responses.stop()
+	r.startRouteMocks()
+	defer r.stopRouteMocks()
+	signals, err := watchSignals(r.page.Context(), r.config.BrowserErrors)
+	if err != nil {
+		return err
+	}
+	defer func() { result.Events = signals.stop() }()
+
+	// failStep 记录失败步骤并保存错误截图
+	failStep := func(stepResult *StepResult, err error) error {
+		stepResult.Status = StatusFailed
+		stepResult.Error = err.Error()
+		// 错误截图
+		if file := browseTemplate.TakeErrorScreenshot(r.page, testCase.Name); file != "" {
+			result.Artifacts = append(result.Artifacts, file)
+			stepResult.Screenshot = file
+		}
+		return &StepError{Index: stepResult.Index, Action: stepResult.Action, Err: err}
+	}
+
 	allStepsCount := len(testCase.Steps)
 	for i := range allStepsCount {
-		step := testCase.Steps[i]
-		fmt.Printf("  [%d/%d] 执行步骤: %s\n", i+1, allStepsCount, step.Action)
-
-		var err error
-		switch step.Action {
-		case "goto":
-			err = r.handleGoto(step)
-		case "input":
-			err = r.handleInput(step)
-		case "click":
-			err = r.handleClick(step)
-		case "assert":
-			err = r.handleAssert(step)
-		case "menu_click":
-			err = r.handleMenuClick(step)
-		case "captcha_input":
-			err = r.handleCaptchaInput(step)
-		case "select_option":
-			err = r.handleSelectOption(step)
-		case "select_options":
-			err = r.handleSelectOptions(step)
-		case "checkbox_toggle":
-			err = r.handleCheckboxToggle(step)
-		case "checkbox_set":
-			err = r.handleCheckboxSet(step)
-		case "checkboxes_set":
-			err = r.handleCheckboxesSet(step)
-		case "radio_select":
-			err = r.handleRadioSelect(step)
-		case "radios_select":
-			err = r.handleRadiosSelect(step)
-		case "table_edit":
-			err = r.handleTableEdit(step)
-		case "table_delete":
-			err = r.handleTableDelete(step)
-		case "table_assert":
-			err = r.handleTableAssert(step)
-		case "search":
-			err = r.handleSearch(step)
-		default:
-			err = fmt.Errorf("未知的 action: %s", step.Action)
+		step := r.vars.resolveStep(testCase.Steps[i])
+		fmt.Fprintf(r.out, "  [%d/%d] 执行步骤: %s\n", i+1, allStepsCount, step.Action)
+
+		stepResult := &StepResult{
+			Index:     i + 1,
+			Action:    step.Action,
+			Target:    describeStep(step),
+			StartTime: time.Now(),
 		}
-
+		result.Steps = append(result.Steps, stepResult)
+
+		signals.setStep(i + 1)
+		endTraceStep := r.traceStep(i+1, step.Action)
+		err := r.runStepWithRetry(step, stepResult)
+		endTraceStep()
+		if err == nil {
+			// 步骤期间出现的页面错误（未捕获异常、5xx 响应等）按 browser_errors 配置导致步骤失败
+			err = signals.takeFailure()
+		}
+		stepResult.Duration = time.Since(stepResult.StartTime)
 		if err != nil {
-			// 错误截图
-			browseTemplate.TakeErrorScreenshot(r.page)
-			return fmt.Errorf("步骤 [%d] %s 执行失败: %v", i+1, step.Action, err)
+			return failStep(stepResult, err)
 		}
+		stepResult.Status = StatusPassed
+	}
 
-		// 步骤间等待
-		time.Sleep(300 * time.Millisecond)
+	// 最后一个步骤等待页面稳定后才到达的页面错误同样计入该步骤
+	if len(result.Steps) > 0 {
+		if err := signals.takeFailure(); err != nil {
+			return failStep(result.Steps[len(result.Steps)-1], err)
+		}
 	}
 
-	fmt.Printf("✅ UI 用例执行完成: %s\n", testCase.Name)
+	fmt.Fprintf(r.out, "✅ UI 用例执行完成: %s\n", testCase.Name)
 	return nil
 }
 
+// runStepWithRetry 执行单个 UI 步骤，失败时按重试策略重新执行
+func (r *Runner) runStepWithRetry(step browseTemplate.TestStep, stepResult *StepResult) error {
+	retry := r.config.StepRetry
+	if step.Retry != nil {
+		retry = *step.Retry
+	}
+
+	// 等待类步骤匹配的是上一个操作步骤之后发生的事件；请求拦截步骤不操作页面，同样不影响等待
+	passive := isWaitAction(step.Action) || isRouteAction(step.Action)
+	// 切换页面不发出请求，之后的等待步骤仍可匹配打开新页面的操作触发的响应
+	for attempt := 0; ; attempt++ {
+		if !passive && !isPageAction(step.Action) {
+			r.responses.mark()
+		}
+		err := r.executeStep(step)
+		if err == nil && !passive {
+			err = r.settle()
+		}
+		// 步骤期间触发的请求拦截处理失败（如 patch 路径不存在）
+		if routeErr := r.routes.takeError(); err == nil {
+			err = routeErr
+		}
+		if err == nil || attempt >= retry.Times {
+			return err
+		}
+
+		stepResult.FailedAttempts = append(stepResult.FailedAttempts, err.Error())
+		fmt.Fprintf(r.out, "    🔁 步骤重试 (%d/%d): %v\n", attempt+1, retry.Times, err)
+		time.Sleep(time.Duration(retry.IntervalMs) * time.Millisecond)
+	}
+}
+
+// executeStep 根据 action 分发执行单个 UI 步骤
+func (r *Runner) executeStep(step browseTemplate.TestStep) error {
+	switch step.Action {
+	case "goto":
+		return r.handleGoto(step)
+	case "input":
+		return r.handleInput(step)
+	case "click":
+		return r.handleClick(step)
+	case "assert":
+		return r.handleAssert(step)
+	case "menu_click":
+		return r.handleMenuClick(step)
+	case "captcha_input":
+		return r.handleCaptchaInput(step)
+	case "select_option":
+		return r.handleSelectOption(step)
+	case "select_options":
+		return r.handleSelectOptions(step)
+	case "checkbox_toggle":
+		return r.handleCheckboxToggle(step)
+	case "checkbox_set":
+		return r.handleCheckboxSet(step)
+	case "checkboxes_set":
+		return r.handleCheckboxesSet(step)
+	case "radio_select":
+		return r.handleRadioSelect(step)
+	case "radios_select":
+		return r.handleRadiosSelect(step)
+	case "table_edit":
+		return r.handleTableEdit(step)
+	case "table_delete":
+		return r.handleTableDelete(step)
+	case "table_assert":
+		return r.handleTableAssert(step)
+	case "search":
+		return r.handleSearch(step)
+	case "wait_for":
+		return r.handleWaitFor(step)
+	case "wait_for_url":
+		return r.handleWaitForURL(step)
+	case "wait_for_response":
+		return r.handleWaitForResponse(step)
+	case "wait_ms":
+		return r.handleWaitMs(step)
+	case "wait_for_api":
+		return r.handleWaitForAPI(step)
+	case "route_mock":
+		return r.handleRouteMock(step)
+	case "route_clear":
+		return r.handleRouteClear(step)
+	case "switch_page":
+		return r.handleSwitchPage(step)
+	case "close_page":
+		return r.handleClosePage(step)
+	default:
+		return fmt.Errorf("未知的 action: %s", step.Action)
+	}
+}
+
 // runAPITest 新增：API 测试执行逻辑
-func (r *Runner) runAPITest(testCase TestCase) error {
-	fmt.Println("  [API] 正在准备请求...")
+func (r *Runner) runAPITest(testCase TestCase, result *CaseResult) error {
+	resp, err := r.callAPI(*testCase.APIConfig, testCase.APIExpect, result)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(r.out, "✅ API 用例执行通过: Status %d\n", resp.StatusCode)
+	return nil
+}
+
+// callAPI 生成并发送 API 请求（配置了 poll 时轮询直到满足条件），校验期望并保存响应字段
+// API 用例与 UI 步骤中的 wait_for_api 共用
+func (r *Runner) callAPI(config apisTemplate.TestCaseConfig, expect *apisTemplate.ExpectConfig, result *CaseResult) (*apisTemplate.APIResponse, error) {
+	fmt.Fprintln(r.out, "  [API] 正在准备请求...")
 
 	// 1. 获取模板
 	if r.apiTemplates == nil {
-		return fmt.Errorf("API 模板未加载")
+		return nil, fmt.Errorf("API 模板未加载")
 	}
-	tmpl, exists := r.apiTemplates[testCase.APIConfig.Template]
+	tmpl, exists := r.apiTemplates[config.Template]
 	if !exists {
-		return fmt.Errorf("找不到 API 模板: %s", testCase.APIConfig.Template)
+		return nil, fmt.Errorf("找不到 API 模板: %s", config.Template)
 	}
 
 	// 2. 生成请求
-	req, err := apisTemplate.GenerateRequest(tmpl, testCase.APIConfig.Params)
+	params := r.vars.resolveParams(config.Params)
+	req, err := apisTemplate.GenerateRequest(tmpl, params)
 	if err != nil {
-		return fmt.Errorf("生成请求失败: %v", err)
+		return nil, fmt.Errorf("生成请求失败: %v", err)
 	}
 
-	fmt.Printf("  [API] 发送 %s 请求到: %s\n", req.Method, req.URL)
-
 	// 3. 执行请求
-	resp, err := apisTemplate.ExecuteRequest(req)
+	authName := tmpl.Auth
+	if config.Auth != "" {
+		authName = config.Auth
+	}
+	var resp *apisTemplate.APIResponse
+	if config.Poll != nil {
+		resp, err = r.pollAPIRequest(req, authName, params, *config.Poll, expect, result)
+	} else {
+		resp, err = r.sendAPIRequest(req, authName, params, result)
+	}
 	if err != nil {
-		return fmt.Errorf("请求执行失败: %v", err)
+		return nil, err
 	}
 
 	// 4. 验证结果
-	if testCase.APIExpect != nil {
-		if err := apisTemplate.ValidateResponse(resp, *testCase.APIExpect); err != nil {
-			return fmt.Errorf("验证失败: %v", err)
+	if expect != nil {
+		resolved := r.vars.resolveExpect(expect)
+		if err := apisTemplate.ValidateResponse(resp, *resolved); err != nil {
+			return nil, fmt.Errorf("验证失败: %v", err)
 		}
 	}
 
-	fmt.Printf("✅ API 用例执行通过: Status %d\n", resp.StatusCode)
+	// 5. 保存响应中的字段，供后续用例通过 {name} 引用
+	// 数字、布尔等值保留原始类型，后续请求中 "{name}" 独占字段时按原类型替换
+	saved, err := apisTemplate.ExtractSaveResponseValues(resp, tmpl.SaveResponse)
+	if err != nil {
+		return nil, fmt.Errorf("保存响应字段失败: %v", err)
+	}
+	for name, value := range saved {
+		r.vars.SetValue(name, value)
+		text, _ := r.vars.Get(name)
+		fmt.Fprintf(r.out, "  [API] 保存变量 {%s} = %s\n", name, text)
+	}
+
+	return resp, nil
+}
+
+// sendAPIRequest 添加认证信息后发送请求，并记录到用例结果
+// 使用 OAuth2 认证且收到 401 时，丢弃缓存的令牌、重新获取后再发送一次
+func (r *Runner) sendAPIRequest(req apisTemplate.APIRequest, authName string, params []apisTemplate.Param, result *CaseResult) (*apisTemplate.APIResponse, error) {
+	for refreshed := false; ; refreshed = true {
+		sent := req
+		if authName != "" {
+			var err error
+			sent, err = r.auth.Apply(r.apiClient, req, authName, params)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		header, query := r.auth.CredentialKeys(authName)
+		exchange := newAPIExchange(sent, header, query)
+		fmt.Fprintf(r.out, "  [API] 发送 %s 请求到: %s\n", sent.Method, exchange.URL)
+		result.API = append(result.API, exchange)
+		start := time.Now()
+		resp, err := r.session.Execute(sent)
+		exchange.Duration = time.Since(start)
+		if err != nil {
+			exchange.Error = err.Error()
+			return nil, fmt.Errorf("请求执行失败: %v", err)
+		}
+		exchange.StatusCode = resp.StatusCode
+		exchange.ResponseHeaders = resp.Header
+		exchange.ResponseBody = resp.RawBody
+
+		if resp.StatusCode == 401 && authName != "" && !refreshed && r.auth.Invalidate(authName) {
+			fmt.Fprintf(r.out, "  [API] 🔑 令牌已失效 (401)，重新获取认证 '%s' 的令牌后重试\n", authName)
+			continue
+		}
+		return resp, nil
+	}
+}
+
+// runToolTest System Tool 测试执行逻辑
+func (r *Runner) runToolTest(testCase TestCase, result *CaseResult) error {
+	// 1. 获取模板
+	if r.toolTemplates == nil {
+		return fmt.Errorf("工具模板未加载")
+	}
+	tmpl, exists := r.toolTemplates[testCase.ToolConfig.Template]
+	if !exists {
+		return fmt.Errorf("找不到工具模板: %s", testCase.ToolConfig.Template)
+	}
+
+	// 2. 生成命令（用例断言优先于模板断言）
+	params := r.vars.resolveParams(testCase.ToolConfig.Params)
+	cmd, err := toolsTemplate.GenerateCommand(tmpl, params)
+	if err != nil {
+		return fmt.Errorf("生成命令失败: %v", err)
+	}
+	expect := cmd.Expect
+	if testCase.ToolConfig.Expect != nil {
+		if expect, err = toolsTemplate.ResolveExpect(testCase.ToolConfig.Expect, params); err != nil {
+			return fmt.Errorf("生成命令失败: %v", err)
+		}
+	}
+	timeout := cmd.Timeout
+	if testCase.ToolConfig.Timeout > 0 {
+		timeout = testCase.ToolConfig.Timeout
+	}
+
+	fmt.Fprintf(r.out, "  [Tool] 执行命令: %s\n", cmd.Command)
+
+	// 3. 执行命令
+	toolResult, err := toolsTemplate.ExecuteCommand(cmd.Command, time.Duration(timeout)*time.Millisecond)
+	result.Tool = toolResult
+	if err != nil {
+		return err
+	}
+
+	// 4. 验证结果
+	if err := toolsTemplate.ValidateResult(toolResult, cmd.ExpectedOutput, expect); err != nil {
+		return fmt.Errorf("验证失败: %v", err)
+	}
+
+	fmt.Fprintf(r.out, "✅ Tool 用例执行通过: 退出码 %d\n", toolResult.ExitCode)
 	return nil
 }
 
-// RunTestSuite 执行测试套件
-func (r *Runner) RunTestSuite(suite TestSuite) error {
+// RunTestSuite 执行测试套件中的所有用例并汇总结果
+// 默认单个用例失败不会中断后续用例；开启 fail_fast 后，失败之后的用例均标记为跳过
+// 设置了并行数时按 worker 池并发执行，结果仍按用例定义顺序排列
+func (r *Runner) RunTestSuite(name string, suite TestSuite) *SuiteResult {
+	suiteResult := &SuiteResult{
+		Name:      name,
+		StartTime: time.Now(),
+	}
+
+	if r.parallel > 1 {
+		suiteResult.Cases = r.runParallel(suite)
+		suiteResult.Duration = time.Since(suiteResult.StartTime)
+		return suiteResult
+	}
+
+	stopped := false
 	for _, testCase := range suite {
-		if err := r.RunTestCase(testCase); err != nil {
-			return err
+		if stopped {
+			suiteResult.Cases = append(suiteResult.Cases, failFastSkipped(testCase))
+			continue
+		}
+
+		caseResult := r.RunTestCase(testCase)
+		suiteResult.Cases = append(suiteResult.Cases, caseResult)
+		if caseResult.Status == StatusFailed && r.failFast {
+			fmt.Fprintln(r.out, "⛔ fail_fast 已开启，停止执行剩余用例")
+			stopped = true
 		}
 	}
-	return nil
+
+	suiteResult.Duration = time.Since(suiteResult.StartTime)
+	return suiteResult
 }
 
-// RunTestSuiteFromFile 从文件加载并执行测试套件
-func (r *Runner) RunTestSuiteFromFile(filePath string) error {
+// failFastSkipped 生成因 fail_fast 未执行的用例结果
+func failFastSkipped(testCase TestCase) *CaseResult {
+	return &CaseResult{
+		Name:   testCase.Name,
+		Status: StatusSkipped,
+		Error:  "fail_fast: 前序用例失败，未执行",
+	}
+}
+
+// LoadTestSuite 从文件加载测试套件
+func LoadTestSuite(filePath string) (TestSuite, error) {
 	content, err := os.ReadFile(filePath)
 	if err != nil {
-		return fmt.Errorf("读取测试文件失败: %v", err)
+		return nil, fmt.Errorf("读取测试文件失败: %v", err)
 	}
 
 	var suite TestSuite
 	err = json.Unmarshal(content, &suite)
 	if err != nil {
-		return fmt.Errorf("解析测试文件失败: %v", err)
+		return nil, fmt.Errorf("解析测试文件失败: %v", err)
 	}
 
-	return r.RunTestSuite(suite)
+	// 展开数据驱动用例
+	baseDir := filepath.Dir(filePath)
+	suite, err = ExpandDataDriven(suite, baseDir)
+	if err != nil {
+		return nil, err
+	}
+
+	// 加载 schema 引用的文件，相对路径基于测试文件所在目录
+	for i := range suite {
+		if err := loadSchemaFiles(suite[i], baseDir); err != nil {
+			return nil, fmt.Errorf("用例 '%s' %v", suite[i].Name, err)
+		}
+		suite[i] = resolveMockFiles(suite[i], baseDir)
+	}
+	return suite, nil
+}
+
+// loadSchemaFiles 加载用例 expect、api_config.poll.until 以及 wait_for_api 步骤 api.poll.until 中引用的 schema 文件
+func loadSchemaFiles(testCase TestCase, baseDir string) error {
+	if err := apisTemplate.LoadSchemaFile(testCase.APIExpect, baseDir); err != nil {
+		return err
+	}
+	if config := testCase.APIConfig; config != nil && config.Poll != nil {
+		if err := apisTemplate.LoadSchemaFile(config.Poll.Until, baseDir); err != nil {
+			return fmt.Errorf("poll.until %v", err)
+		}
+	}
+	for i, step := range testCase.Steps {
+		if step.API == nil || step.API.Poll == nil {
+			continue
+		}
+		if err := apisTemplate.LoadSchemaFile(step.API.Poll.Until, baseDir); err != nil {
+			return fmt.Errorf("步骤 [%d] poll.until %v", i+1, err)
+		}
+	}
+	return nil
+}
+
+// RunTestSuiteFromFile 从文件加载并执行测试套件
+// 返回的 error 仅表示加载失败，用例失败记录在 SuiteResult 中
+func (r *Runner) RunTestSuiteFromFile(filePath string) (*SuiteResult, error) {
+	suite, err := LoadTestSuite(filePath)
+	if err != nil {
+		return nil, err
+	}
+	return r.RunTestSuite(filePath, suite), nil
 }
 
 // handleGoto 处理页面跳转
@@ -195,20 +641,18 @@ func (r *Runner) handleGoto(step browseTemplate.TestStep) error {
 	return err
 }
 
-// handleInput 处理输入操作
+// handleInput 处理输入操作，text 为空时清空输入框（如数据驱动中值为空的列）
 func (r *Runner) handleInput(step browseTemplate.TestStep) error {
 	if step.Selector == nil {
 		return errors.New("input action 需要提供 selector")
 	}
-	if step.Text == "" {
-		return errors.New("input action 需要提供 text")
-	}
 
 	// 定位元素
 	selector := utils.SelectorConfig{
 		Type:  step.Selector.Type,
 		Value: step.Selector.Value,
 		Scope: step.Selector.Scope,
+		Frame: step.Selector.Frame,
 	}
 	element, err := utils.LocateElement(r.page, selector)
 	if err != nil {
@@ -223,7 +667,7 @@ func (r *Runner) handleInput(step browseTemplate.TestStep) error {
 
 	// 如果有expect验证，执行验证
 	if step.Expect != nil {
-		return r.verifyExpect(step.Expect, step.Text)
+		return r.verifyExpect(step.Expect, step.Text, step.Selector.Frame)
 	}
 
 	return nil
@@ -240,14 +684,18 @@ func (r *Runner) handleClick(step browseTemplate.TestStep) error {
 		Type:  step.Selector.Type,
 		Value: step.Selector.Value,
 		Scope: step.Selector.Scope,
+		Frame: step.Selector.Frame,
 	}
 	element, err := utils.LocateElement(r.page, selector)
 	if err != nil {
 		return fmt.Errorf("定位元素失败: %v", err)
 	}
 
-	// 确保元素在可视区域
+	// 确保元素在可视区域，force 点击会跳过可操作性检查，因此先等待元素可见且可用
 	_ = element.ScrollIntoViewIfNeeded()
+	if err := utils.WaitForElementReady(element, float64(r.config.Timeout)); err != nil {
+		return err
+	}
 
 	// 使用 Playwright 点击元素（force 避免因轻微遮挡导致无法点击）
 	err = element.Click(playwright.ElementHandleClickOptions{
@@ -257,9 +705,6 @@ func (r *Runner) handleClick(step browseTemplate.TestStep) error {
 		return fmt.Errorf("点击失败: %v", err)
 	}
 
-	// 等待页面响应
-	time.Sleep(500 * time.Millisecond)
-
 	return nil
 }
 
@@ -274,6 +719,7 @@ func (r *Runner) handleAssert(step browseTemplate.TestStep) error {
 		Type:  step.Selector.Type,
 		Value: step.Selector.Value,
 		Scope: step.Selector.Scope,
+		Frame: step.Selector.Frame,
 	}
 	element, err := utils.LocateElement(r.page, selector)
 	if err != nil {
@@ -291,18 +737,19 @@ func (r *Runner) handleAssert(step browseTemplate.TestStep) error {
 
 	// 如果有expect配置，进行更详细的验证
 	if step.Expect != nil {
-		return r.verifyExpect(step.Expect, "")
+		return r.verifyExpect(step.Expect, "", step.Selector.Frame)
 	}
 
 	return nil
 }
 
-// verifyExpect 验证期望结果
-func (r *Runner) verifyExpect(expect *browseTemplate.ExpectConfig, inputText string) error {
+// verifyExpect 验证期望结果，frame 为步骤选择器所在的 iframe，期望验证的元素在同一个 iframe 中查找
+func (r *Runner) verifyExpect(expect *browseTemplate.ExpectConfig, inputText string, frame *utils.FrameConfig) error {
 	// 定位期望验证的元素
 	element, err := utils.LocateElement(r.page, utils.SelectorConfig{
 		Type:  expect.Type,
 		Value: expect.Value,
+		Frame: frame,
 	})
 	if err != nil {
 		return fmt.Errorf("定位期望元素失败: %v", err)
@@ -363,7 +810,7 @@ func (r *Runner) handleMenuClick(step browseTemplate.TestStep) error {
 		return errors.New("menu_click action 需要提供 menu_path")
 	}
 
-	return utils.ClickMenu(r.page, step.MenuPath)
+	return utils.ClickMenu(r.page, step.MenuPath, float64(r.config.Timeout))
 }
 
 // handleCaptchaInput 处理验证码识别和输入操作
@@ -388,11 +835,13 @@ func (r *Runner) handleCaptchaInput(step browseTemplate.TestStep) error {
 		Type:  step.Captcha.ImageSelector.Type,
 		Value: step.Captcha.ImageSelector.Value,
 		Scope: step.Captcha.ImageSelector.Scope,
+		Frame: step.Captcha.ImageSelector.Frame,
 	}
 	inputSelector := utils.SelectorConfig{
 		Type:  step.Captcha.InputSelector.Type,
 		Value: step.Captcha.InputSelector.Value,
 		Scope: step.Captcha.InputSelector.Scope,
+		Frame: step.Captcha.InputSelector.Frame,
 	}
 
 	_, err := utils.SolveAndInputCaptcha(r.page, imageSelector, inputSelector)
@@ -412,9 +861,10 @@ func (r *Runner) handleSelectOption(step browseTemplate.TestStep) error {
 		Type:  step.Selector.Type,
 		Value: step.Selector.Value,
 		Scope: step.Selector.Scope,
+		Frame: step.Selector.Frame,
 	}
 
-	return utils.SelectOption(r.page, selector, step.Text)
+	return utils.SelectOption(r.page, selector, step.Text, float64(r.config.Timeout))
 }
 
 // handleCheckboxToggle 处理复选框切换操作
@@ -427,6 +877,7 @@ func (r *Runner) handleCheckboxToggle(step browseTemplate.TestStep) error {
 		Type:  step.Selector.Type,
 		Value: step.Selector.Value,
 		Scope: step.Selector.Scope,
+		Frame: step.Selector.Frame,
 	}
 
 	return utils.ToggleCheckbox(r.page, selector)
@@ -445,6 +896,7 @@ func (r *Runner) handleCheckboxSet(step browseTemplate.TestStep) error {
 		Type:  step.Selector.Type,
 		Value: step.Selector.Value,
 		Scope: step.Selector.Scope,
+		Frame: step.Selector.Frame,
 	}
 
 	return utils.SetCheckbox(r.page, selector, *step.Checked)
@@ -460,6 +912,7 @@ func (r *Runner) handleRadioSelect(step browseTemplate.TestStep) error {
 		Type:  step.Selector.Type,
 		Value: step.Selector.Value,
 		Scope: step.Selector.Scope,
+		Frame: step.Selector.Frame,
 	}
 
 	return utils.SelectRadio(r.page, selector)
@@ -478,9 +931,10 @@ func (r *Runner) handleSelectOptions(step browseTemplate.TestStep) error {
 		Type:  step.Selector.Type,
 		Value: step.Selector.Value,
 		Scope: step.Selector.Scope,
+		Frame: step.Selector.Frame,
 	}
 
-	return utils.SelectOptions(r.page, selector, step.Options)
+	return utils.SelectOptions(r.page, selector, step.Options, float64(r.config.Timeout))
 }
 
 // handleCheckboxesSet 处理批量复选框设置操作
@@ -498,6 +952,7 @@ func (r *Runner) handleCheckboxesSet(step browseTemplate.TestStep) error {
 			Type:  sel.Type,
 			Value: sel.Value,
 			Scope: sel.Scope,
+			Frame: sel.Frame,
 		}
 	}
 
@@ -516,6 +971,7 @@ func (r *Runner) handleRadiosSelect(step browseTemplate.TestStep) error {
 			Type:  sel.Type,
 			Value: sel.Value,
 			Scope: sel.Scope,
+			Frame: sel.Frame,
 		}
 	}
 
@@ -535,6 +991,7 @@ func (r *Runner) handleTableEdit(step browseTemplate.TestStep) error {
 	tableSelector := utils.SelectorConfig{
 		Type:  step.Table.Selector.Type,
 		Value: step.Table.Selector.Value,
+		Frame: step.Table.Selector.Frame,
 	}
 
 	rowConfig := utils.TableRowConfig{
@@ -563,6 +1020,7 @@ func (r *Runner) handleTableDelete(step browseTemplate.TestStep) error {
 	tableSelector := utils.SelectorConfig{
 		Type:  step.Table.Selector.Type,
 		Value: step.Table.Selector.Value,
+		Frame: step.Table.Selector.Frame,
 	}
 
 	rowConfig := utils.TableRowConfig{
@@ -597,6 +1055,7 @@ func (r *Runner) handleTableAssert(step browseTemplate.TestStep) error {
 	tableSelector := utils.SelectorConfig{
 		Type:  step.Table.Selector.Type,
 		Value: step.Table.Selector.Value,
+		Frame: step.Table.Selector.Frame,
 	}
 
 	rowConfig := utils.TableRowConfig{
@@ -636,6 +1095,7 @@ func (r *Runner) handleSearch(step browseTemplate.TestStep) error {
 			selector := utils.SelectorConfig{
 				Type:  input.Selector.Type,
 				Value: input.Selector.Value,
+				Frame: input.Selector.Frame,
 			}
 
 			element, err := utils.LocateElement(r.page, selector)
@@ -647,8 +1107,6 @@ func (r *Runner) handleSearch(step browseTemplate.TestStep) error {
 			if err != nil {
 				return fmt.Errorf("输入查询条件失败: %v", err)
 			}
-
-			time.Sleep(200 * time.Millisecond)
 		}
 	}
 
@@ -656,6 +1114,7 @@ func (r *Runner) handleSearch(step browseTemplate.TestStep) error {
 	buttonSelector := utils.SelectorConfig{
 		Type:  step.Search.Button.Type,
 		Value: step.Search.Button.Value,
+		Frame: step.Search.Button.Frame,
 	}
 
 	buttonElement, err := utils.LocateElement(r.page, buttonSelector)
@@ -668,8 +1127,6 @@ func (r *Runner) handleSearch(step browseTemplate.TestStep) error {
 		return fmt.Errorf("点击查询按钮失败: %v", err)
 	}
 
-	// 等待查询结果
-	time.Sleep(500 * time.Millisecond)
-
+	// 查询结果由步骤后的自动等待（网络空闲、加载遮罩消失）保证
 	return nil
 }
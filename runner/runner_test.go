@@ -0,0 +1,895 @@
+package runner
+
+import (
+	apisTemplate "autotest/apis-template"
+	browseTemplate "autotest/browse-template"
+	"autotest/browse-template/utils"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// newTestServer 模拟登录 / 新增 / 删除接口
+func newTestServer(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch req.URL.Path {
+		case "/api/login":
+			json.NewEncoder(w).Encode(map[string]interface{}{"code": 0, "token": "t-123"})
+		case "/api/add":
+			if req.Header.Get("Authorization") != "Bearer t-123" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"code": 0, "data": map[string]interface{}{"id": 42}})
+		case "/api/delete":
+			var body map[string]interface{}
+			json.NewDecoder(req.Body).Decode(&body)
+			json.NewEncoder(w).Encode(map[string]interface{}{"code": 0, "deleted": body["id"]})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func newTestTemplates(baseURL string) apisTemplate.APITemplates {
+	return apisTemplate.APITemplates{
+		"login": {
+			URL:          baseURL + "/api/login",
+			Method:       "post",
+			SaveResponse: map[string]string{"token": "response.token"},
+		},
+		"add": {
+			URL:          baseURL + "/api/add",
+			Method:       "post",
+			Headers:      map[string]string{"Authorization": "Bearer {token}"},
+			SaveResponse: map[string]string{"item_id": "response.data.id"},
+		},
+		"delete": {
+			URL:    baseURL + "/api/delete",
+			Method: "post",
+			Data:   map[string]interface{}{"id": "{id}"},
+		},
+	}
+}
+
+func TestRunTestSuite_SaveResponseChain(t *testing.T) {
+	server := newTestServer(t)
+	defer server.Close()
+
+	r := NewRunner(nil, newTestTemplates(server.URL))
+	suite := TestSuite{
+		{Name: "登录", APIConfig: &apisTemplate.TestCaseConfig{Template: "login"}},
+		{Name: "新增", APIConfig: &apisTemplate.TestCaseConfig{Template: "add"}},
+		{
+			Name: "删除",
+			APIConfig: &apisTemplate.TestCaseConfig{
+				Template: "delete",
+				Params:   []apisTemplate.Param{{Key: "{id}", Value: "{item_id}"}},
+			},
+			APIExpect: &apisTemplate.ExpectConfig{Status: 200, Body: map[string]interface{}{"deleted": "42"}},
+		},
+	}
+
+	result := r.RunTestSuite("chain", suite)
+	for _, c := range result.Cases {
+		if c.Status != StatusPassed {
+			t.Errorf("用例 %s 期望通过, 实际 %s: %s", c.Name, c.Status, c.Error)
+		}
+	}
+	if api := result.Cases[2].API; len(api) != 1 || api[0].StatusCode != 200 || !strings.Contains(api[0].RequestBody, "42") {
+		t.Errorf("API 请求记录不正确: %+v", api)
+	}
+	if token, _ := r.Variables().Get("token"); token != "t-123" {
+		t.Errorf("变量 token 期望 't-123', 实际 '%s'", token)
+	}
+}
+
+func TestRunTestSuite_ContinueAndFailFast(t *testing.T) {
+	server := newTestServer(t)
+	defer server.Close()
+
+	suite := TestSuite{
+		{Name: "缺少模板", APIConfig: &apisTemplate.TestCaseConfig{Template: "missing"}},
+		{Name: "跳过", Skip: true, APIConfig: &apisTemplate.TestCaseConfig{Template: "login"}},
+		{Name: "登录", APIConfig: &apisTemplate.TestCaseConfig{Template: "login"}},
+	}
+
+	r := NewRunner(nil, newTestTemplates(server.URL))
+	summary := r.RunTestSuite("continue", suite).Summary()
+	if summary.Total != 3 || summary.Failed != 1 || summary.Skipped != 1 || summary.Passed != 1 {
+		t.Errorf("默认模式统计错误: %+v", summary)
+	}
+
+	r = NewRunner(nil, newTestTemplates(server.URL))
+	r.SetFailFast(true)
+	result := r.RunTestSuite("fail_fast", suite)
+	summary = result.Summary()
+	if summary.Failed != 1 || summary.Skipped != 2 || summary.Passed != 0 {
+		t.Errorf("fail_fast 模式统计错误: %+v", summary)
+	}
+	if !strings.Contains(result.Cases[0].Error, "找不到 API 模板") {
+		t.Errorf("失败原因不正确: %s", result.Cases[0].Error)
+	}
+}
+
+func TestWriteJUnitReport(t *testing.T) {
+	suite := &SuiteResult{
+		Name:     "testcase/demo.json",
+		Duration: 3 * time.Second,
+		Cases: []*CaseResult{
+			{Name: "登录", Status: StatusPassed, Duration: time.Second},
+			{
+				Name:         "新增 <策略>",
+				Status:       StatusFailed,
+				Duration:     2 * time.Second,
+				Error:        (&StepError{Index: 3, Action: "click", Err: errors.New("定位元素失败")}).Error(),
+				FailedStep:   3,
+				FailedAction: "click",
+				Artifacts:    []string{"assets/errors/error_1.png"},
+				Trace:        "assets/traces/新增_1.zip",
+			},
+			{Name: "删除", Status: StatusSkipped},
+		},
+	}
+
+	path := filepath.Join(t.TempDir(), "reports", "junit.xml")
+	if err := WriteJUnitReport(path, suite); err != nil {
+		t.Fatalf("WriteJUnitReport 出错: %v", err)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("读取报告失败: %v", err)
+	}
+
+	report := string(content)
+	for _, want := range []string{
+		`<testsuites tests="3" failures="1" skipped="1"`,
+		`<testcase name="新增 &lt;策略&gt;" classname="testcase/demo.json" time="2.000">`,
+		`<failure message="步骤 [3] click 执行失败" type="click">`,
+		"step: 3",
+		"[[ATTACHMENT|",
+		"error_1.png]]&#xA;[[ATTACHMENT|",
+		"新增_1.zip]]</system-out>",
+		"<skipped></skipped>",
+	} {
+		if !strings.Contains(report, want) {
+			t.Errorf("报告缺少内容 %q:\n%s", want, report)
+		}
+	}
+}
+
+func TestParseReportSpec(t *testing.T) {
+	spec, err := ParseReportSpec("junit=build/junit.xml")
+	if err != nil || spec.Format != "junit" || spec.Path != "build/junit.xml" {
+		t.Errorf("解析结果错误: %+v, %v", spec, err)
+	}
+	for _, bad := range []string{"junit", "=a.xml", "pdf=a.pdf"} {
+		if _, err := ParseReportSpec(bad); err == nil {
+			t.Errorf("参数 '%s' 应返回错误", bad)
+		}
+	}
+}
+
+func TestWriteHTMLReport(t *testing.T) {
+	dir := t.TempDir()
+	shot := filepath.Join(dir, "errors", "error_1.png")
+	os.MkdirAll(filepath.Dir(shot), 0755)
+	os.WriteFile(shot, []byte("\x89PNG fake"), 0644)
+
+	suite := &SuiteResult{
+		Name: "testcase/demo.json",
+		Cases: []*CaseResult{
+			{
+				Name:      "新增策略",
+				Status:    StatusFailed,
+				Error:     "步骤 [2] click 执行失败: 定位元素失败",
+				Artifacts: []string{shot},
+				Video:     filepath.Join(dir, "videos", "v1.webm"),
+				Trace:     filepath.Join(dir, "traces", "t1.zip"),
+				Steps: []*StepResult{
+					{Index: 1, Action: "goto", Target: "https://example.com", Status: StatusPassed},
+					{Index: 2, Action: "click", Target: "button=<新建>", Status: StatusFailed, Error: "定位元素失败"},
+				},
+			},
+			{
+				Name:   "登录接口",
+				Status: StatusPassed,
+				API: []*APIExchange{{
+					Method:       "POST",
+					URL:          "http://localhost/api/login",
+					RequestBody:  `{"username": "admin"}`,
+					StatusCode:   200,
+					ResponseBody: `{"token":"t-123"}`,
+				}},
+			},
+			{
+				Name:           "删除策略",
+				Status:         StatusPassed,
+				FailedAttempts: []*CaseResult{{Name: "删除策略", Status: StatusFailed, Error: "超时"}},
+			},
+		},
+	}
+
+	path := filepath.Join(dir, "report.html")
+	if err := WriteHTMLReport(path, suite); err != nil {
+		t.Fatalf("WriteHTMLReport 出错: %v", err)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("读取报告失败: %v", err)
+	}
+
+	report := string(content)
+	for _, want := range []string{
+		"button=&lt;新建&gt;",
+		"data:image/png;base64,",
+		`src="videos/v1.webm"`,
+		`href="traces/t1.zip"`,
+		"http://localhost/api/login",
+		`&#34;token&#34;: &#34;t-123&#34;`,
+		"<span>重试后通过: 1</span>",
+	} {
+		if !strings.Contains(report, want) {
+			t.Errorf("报告缺少内容 %q", want)
+		}
+	}
+	if strings.Contains(report, "<script src") || strings.Contains(report, "<link") {
+		t.Error("报告不应引用外部资源")
+	}
+}
+
+func TestRunTestCase_StorageStateMissing(t *testing.T) {
+	created := 0
+	r := NewRunner(nil, nil)
+	r.SetContextFactory(func(storageStatePath string) (playwright.BrowserContext, playwright.Page, error) {
+		created++
+		return nil, nil, errors.New("no browser")
+	})
+
+	result := r.RunTestCase(TestCase{
+		Name:            "复用未保存的登录状态",
+		UseStorageState: "never-saved-admin",
+		Steps:           []browseTemplate.TestStep{{Action: "goto", URL: "https://example.com"}},
+	})
+	if result.Status != StatusFailed || !strings.Contains(result.Error, "登录状态 'never-saved-admin' 不存在") {
+		t.Errorf("期望因登录状态不存在而失败, 实际 %s: %s", result.Status, result.Error)
+	}
+	if created != 0 {
+		t.Errorf("登录状态不存在时不应创建上下文")
+	}
+
+	result = r.RunTestCase(TestCase{
+		Name:  "创建上下文失败",
+		Steps: []browseTemplate.TestStep{{Action: "goto", URL: "https://example.com"}},
+	})
+	if result.Status != StatusFailed || created != 1 {
+		t.Errorf("期望创建上下文一次并失败, 实际 %s (创建 %d 次)", result.Status, created)
+	}
+}
+
+func TestPlanSegments(t *testing.T) {
+	r := NewRunner(nil, apisTemplate.APITemplates{
+		"login":  {SaveResponse: map[string]string{"token": "response.token"}},
+		"status": {},
+	})
+	r.SetContextFactory(func(string) (playwright.BrowserContext, playwright.Page, error) {
+		return nil, nil, errors.New("unused")
+	})
+	suite := TestSuite{
+		{Name: "a"},
+		{Name: "b", Group: "g"},
+		{Name: "c"},
+		{Name: "d", Group: "g"},
+		{Name: "e", Serial: true},
+		{Name: "f"},
+		{Name: "g", SaveStorageState: "admin"},
+		{Name: "h", Group: "g"},
+		{Name: "i", APIConfig: &apisTemplate.TestCaseConfig{Template: "login"}},
+		{Name: "j", APIConfig: &apisTemplate.TestCaseConfig{Template: "status"}},
+		{Name: "k", Steps: []browseTemplate.TestStep{{Action: "wait_for_api", API: &apisTemplate.TestCaseConfig{Template: "login"}}}},
+		{Name: "l"},
+	}
+
+	got := r.planSegments(suite)
+	want := [][]executionUnit{
+		{{0}, {1, 3}, {2}},
+		{{4}},
+		{{5}},
+		{{6}},
+		{{7}},
+		{{8}},
+		{{9}},
+		{{10}},
+		{{11}},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("阶段数期望 %d, 实际 %d: %v", len(want), len(got), got)
+	}
+	for i := range want {
+		if len(got[i]) != len(want[i]) {
+			t.Fatalf("阶段 %d 期望 %v, 实际 %v", i, want[i], got[i])
+		}
+		for j := range want[i] {
+			if len(got[i][j]) != len(want[i][j]) {
+				t.Fatalf("阶段 %d 期望 %v, 实际 %v", i, want[i], got[i])
+			}
+			for k := range want[i][j] {
+				if got[i][j][k] != want[i][j][k] {
+					t.Fatalf("阶段 %d 期望 %v, 实际 %v", i, want[i], got[i])
+				}
+			}
+		}
+	}
+}
+
+func TestRunTestSuite_Parallel(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+		w.Write([]byte(`{"code": 0}`))
+	}))
+	defer server.Close()
+
+	templates := apisTemplate.APITemplates{
+		"slow": {URL: server.URL + "/slow", Method: "get"},
+	}
+	var suite TestSuite
+	for _, name := range []string{"c1", "c2", "c3", "c4"} {
+		suite = append(suite, TestCase{Name: name, APIConfig: &apisTemplate.TestCaseConfig{Template: "slow"}})
+	}
+	suite = append(suite, TestCase{Name: "c5", APIConfig: &apisTemplate.TestCaseConfig{Template: "missing"}})
+
+	var out strings.Builder
+	r := NewRunner(nil, templates)
+	r.out = &out
+	r.SetParallel(4)
+
+	start := time.Now()
+	result := r.RunTestSuite("parallel", suite)
+	if elapsed := time.Since(start); elapsed > 700*time.Millisecond {
+		t.Errorf("并行执行耗时过长: %s", elapsed)
+	}
+
+	for i, c := range result.Cases {
+		if c.Name != suite[i].Name {
+			t.Errorf("结果顺序错误: 位置 %d 期望 %s, 实际 %s", i, suite[i].Name, c.Name)
+		}
+	}
+	if summary := result.Summary(); summary.Passed != 4 || summary.Failed != 1 {
+		t.Errorf("统计错误: %+v", summary)
+	}
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n")[1:] {
+		if !strings.HasPrefix(line, "[w") {
+			t.Errorf("并行日志缺少 worker 前缀: %s", line)
+		}
+	}
+}
+
+func TestExpandDataDriven(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "users.csv"), []byte("\xef\xbb\xbfusername,password,message\nsecadmin,Admin@123,ok\n\"audit,admin\",x,密码错误\n"), 0644)
+
+	raw := `[
+		{
+			"name": "登录",
+			"data": [{"username": "sysadmin", "password": 123456, "message": "ok"}],
+			"data_file": "users.csv",
+			"steps": [
+				{"action": "input", "selector": {"type": "field", "value": "用户名"}, "text": "{username}"},
+				{"action": "assert", "selector": {"type": "text", "value": "{message}"}}
+			]
+		},
+		{
+			"name": "登录接口",
+			"data": [{"username": "secadmin", "message": "ok", "count": 3, "enabled": true}],
+			"api_config": {"template": "call_login", "params": [{"key": "{password}", "value": "p-{username}"}]},
+			"expect": {"status": 200, "body": {"message": "{message}"}}
+		},
+		{"name": "普通用例", "steps": [{"action": "goto", "url": "https://example.com/{username}"}]}
+	]`
+	var suite TestSuite
+	if err := json.Unmarshal([]byte(raw), &suite); err != nil {
+		t.Fatalf("JSON 解析失败: %v", err)
+	}
+
+	expanded, err := ExpandDataDriven(suite, dir)
+	if err != nil {
+		t.Fatalf("ExpandDataDriven 出错: %v", err)
+	}
+	if len(expanded) != 5 {
+		t.Fatalf("期望展开为 5 个用例, 实际 %d", len(expanded))
+	}
+
+	names := []string{"登录 [row 1: sysadmin]", "登录 [row 2: secadmin]", "登录 [row 3: audit,admin]", "登录接口 [row 1: secadmin]", "普通用例"}
+	for i, name := range names {
+		if expanded[i].Name != name {
+			t.Errorf("用例 %d 名称期望 '%s', 实际 '%s'", i, name, expanded[i].Name)
+		}
+	}
+	if got := expanded[2].Steps[0].Text; got != "audit,admin" {
+		t.Errorf("步骤 text 替换错误: %s", got)
+	}
+	if got := expanded[2].Steps[1].Selector.Value; got != "密码错误" {
+		t.Errorf("选择器 value 替换错误: %s", got)
+	}
+	if suite[0].Steps[0].Text != "{username}" {
+		t.Errorf("原始用例被修改: %s", suite[0].Steps[0].Text)
+	}
+
+	api := expanded[3]
+	params := api.APIConfig.Params
+	if params[0].Value != "p-secadmin" {
+		t.Errorf("api_config.params 替换错误: %+v", params)
+	}
+	found := false
+	for _, p := range params {
+		if p.Key == "{username}" && p.Value == "secadmin" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("行数据未追加为参数: %+v", params)
+	}
+	for _, p := range params {
+		if (p.Key == "{count}" && string(p.Raw) != "3") || (p.Key == "{enabled}" && string(p.Raw) != "true") {
+			t.Errorf("数字、布尔列应保留类型: %+v", p)
+		}
+	}
+	if api.APIExpect.Body["message"] != "ok" {
+		t.Errorf("expect.body 替换错误: %v", api.APIExpect.Body)
+	}
+
+	if expanded[4].Steps[0].URL != "https://example.com/{username}" {
+		t.Errorf("非数据驱动用例不应被修改: %s", expanded[4].Steps[0].URL)
+	}
+}
+
+func TestVariables_SetValue(t *testing.T) {
+	vars := NewVariables()
+	vars.SetValue("id", float64(7))
+	vars.SetValue("deleted_at", nil)
+	vars.SetValue("name", "alice")
+
+	for name, want := range map[string]string{"id": "7", "deleted_at": "", "name": "alice"} {
+		if got, _ := vars.Get(name); got != want {
+			t.Errorf("变量 %s 的文本 = %q, want %q", name, got, want)
+		}
+	}
+	raws := map[string]string{}
+	for _, p := range vars.Params() {
+		raws[p.Key] = string(p.Raw)
+	}
+	if raws["{id}"] != "7" || raws["{deleted_at}"] != "null" || raws["{name}"] != "" {
+		t.Errorf("变量的原始 JSON 错误: %v", raws)
+	}
+}
+
+func TestNewURLMatcher(t *testing.T) {
+	cases := []struct {
+		pattern string
+		url     string
+		want    bool
+	}{
+		{"/api/users", "https://example.com/api/users?page=1", true},
+		{"/api/users", "https://example.com/api/roles", false},
+		{"**/api/users*", "https://example.com/api/users?page=1", true},
+		{"**/api/*/detail", "https://example.com/api/users/detail", true},
+		{"**/api/*/detail", "https://example.com/api/users/1/detail", false},
+		{"/users/\\d+$/", "https://example.com/users/42", true},
+		{"/users/\\d+$/", "https://example.com/users/new", false},
+	}
+	for _, c := range cases {
+		match, err := newURLMatcher(c.pattern)
+		if err != nil {
+			t.Fatalf("解析 '%s' 失败: %v", c.pattern, err)
+		}
+		if got := match(c.url); got != c.want {
+			t.Errorf("'%s' 匹配 '%s' 期望 %v, 实际 %v", c.pattern, c.url, c.want, got)
+		}
+	}
+
+	if _, err := newURLMatcher("/users/(/"); err == nil {
+		t.Error("无效正则应返回错误")
+	}
+	if _, err := parseWaitState("gone"); err == nil {
+		t.Error("未知等待状态应返回错误")
+	}
+}
+
+func TestRunTestCase_Retries(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		calls++
+		if calls < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"code": 0}`))
+	}))
+	defer server.Close()
+
+	templates := apisTemplate.APITemplates{"flaky": {URL: server.URL, Method: "get"}}
+	expect := &apisTemplate.ExpectConfig{Status: 200}
+	retries := 2
+
+	r := NewRunner(nil, templates)
+	result := r.RunTestCase(TestCase{Name: "不稳定接口", Retries: &retries, APIConfig: &apisTemplate.TestCaseConfig{Template: "flaky"}, APIExpect: expect})
+	if result.Status != StatusPassed || len(result.FailedAttempts) != 2 || !result.Flaky() {
+		t.Fatalf("重试后应通过并保留 2 次失败记录: %s, %d", result.Status, len(result.FailedAttempts))
+	}
+	if !strings.Contains(result.FailedAttempts[0].Error, "503") {
+		t.Errorf("失败记录原因不正确: %s", result.FailedAttempts[0].Error)
+	}
+
+	path := filepath.Join(t.TempDir(), "junit.xml")
+	if err := WriteJUnitReport(path, &SuiteResult{Name: "retry", Cases: []*CaseResult{result}}); err != nil {
+		t.Fatalf("WriteJUnitReport 出错: %v", err)
+	}
+	content, _ := os.ReadFile(path)
+	if strings.Count(string(content), "<flakyFailure") != 2 {
+		t.Errorf("JUnit 报告应包含 2 个 flakyFailure:\n%s", content)
+	}
+
+	// 配置中的默认重试次数，用例显式设置 0 时不重试
+	calls = 0
+	config := browseTemplate.DefaultConfig()
+	config.Retries = 1
+	r.SetBrowserConfig(config)
+	if result := r.RunTestCase(TestCase{Name: "默认重试", APIConfig: &apisTemplate.TestCaseConfig{Template: "flaky"}, APIExpect: expect}); result.Status != StatusFailed || len(result.FailedAttempts) != 1 {
+		t.Errorf("默认重试 1 次后应失败: %s, %d", result.Status, len(result.FailedAttempts))
+	}
+	noRetry := 0
+	if result := r.RunTestCase(TestCase{Name: "不重试", Retries: &noRetry, APIConfig: &apisTemplate.TestCaseConfig{Template: "flaky"}, APIExpect: expect}); result.Status != StatusPassed || len(result.FailedAttempts) != 0 {
+		t.Errorf("第 3 次请求应直接通过: %s, %d", result.Status, len(result.FailedAttempts))
+	}
+}
+
+func TestLoadTestSuite_SchemaFile(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "schemas"), 0755)
+	os.WriteFile(filepath.Join(dir, "schemas", "login.json"), []byte(`{"type": "object", "required": ["token"]}`), 0644)
+	os.WriteFile(filepath.Join(dir, "suite.json"), []byte(`[
+		{"name": "登录", "api_config": {"template": "login"}, "expect": {"status": 200, "schema": "schemas/login.json"}},
+		{"name": "内联", "api_config": {"template": "login"}, "expect": {"status": 200, "schema": {"type": "object"}}},
+		{"name": "轮询", "api_config": {"template": "login", "poll": {"until": {"schema": "schemas/login.json"}}}},
+		{"name": "页面轮询", "steps": [{"action": "wait_for_api", "api": {"template": "login", "poll": {"until": {"schema": "schemas/login.json"}}}}]}
+	]`), 0644)
+
+	suite, err := LoadTestSuite(filepath.Join(dir, "suite.json"))
+	if err != nil {
+		t.Fatalf("LoadTestSuite 出错: %v", err)
+	}
+	if !strings.Contains(string(suite[0].APIExpect.Schema), `"required"`) {
+		t.Errorf("schema 文件未加载: %s", suite[0].APIExpect.Schema)
+	}
+	if string(suite[1].APIExpect.Schema) != `{"type": "object"}` {
+		t.Errorf("内联 schema 不应被修改: %s", suite[1].APIExpect.Schema)
+	}
+	if !strings.Contains(string(suite[2].APIConfig.Poll.Until.Schema), `"required"`) {
+		t.Errorf("poll.until 的 schema 文件未加载: %s", suite[2].APIConfig.Poll.Until.Schema)
+	}
+	if !strings.Contains(string(suite[3].Steps[0].API.Poll.Until.Schema), `"required"`) {
+		t.Errorf("wait_for_api 的 schema 文件未加载: %s", suite[3].Steps[0].API.Poll.Until.Schema)
+	}
+
+	os.WriteFile(filepath.Join(dir, "missing.json"), []byte(`[{"name": "缺失", "expect": {"schema": "schemas/none.json"}}]`), 0644)
+	if _, err := LoadTestSuite(filepath.Join(dir, "missing.json")); err == nil {
+		t.Error("schema 文件不存在时应返回错误")
+	}
+	os.WriteFile(filepath.Join(dir, "missing_poll.json"), []byte(`[{"name": "缺失", "api_config": {"template": "login", "poll": {"until": {"schema": "schemas/none.json"}}}}]`), 0644)
+	if _, err := LoadTestSuite(filepath.Join(dir, "missing_poll.json")); err == nil || !strings.Contains(err.Error(), "poll.until") {
+		t.Errorf("poll.until 的 schema 文件不存在时应返回错误: %v", err)
+	}
+}
+
+func TestRunTestCase_AuthProfiles(t *testing.T) {
+	var tokenRequests int
+	valid := ""
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/token":
+			req.ParseForm()
+			if req.Form.Get("grant_type") != "client_credentials" || req.Form.Get("client_secret") != "s3cret" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			tokenRequests++
+			valid = "tok-" + string(rune('0'+tokenRequests))
+			w.Write([]byte(`{"access_token":"` + valid + `","token_type":"bearer","expires_in":3600}`))
+		case "/revoke":
+			valid = ""
+		default:
+			auth := req.Header.Get("Authorization")
+			if key := req.URL.Query().Get("key"); key != "" {
+				auth = "key " + key
+			}
+			if auth == "Bearer "+valid && valid != "" || auth == "Basic YWRtaW46cHc=" || auth == "Bearer fixed" || auth == "key k1" {
+				w.Write([]byte(`{"auth":"` + auth + `"}`))
+				return
+			}
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer server.Close()
+
+	templates := apisTemplate.APITemplates{
+		"data":   {URL: server.URL + "/data", Method: "get", Auth: "service"},
+		"revoke": {URL: server.URL + "/revoke", Method: "post"},
+	}
+	r := NewRunner(nil, templates)
+	r.SetAuthProfiles(apisTemplate.AuthProfiles{
+		"service": {Type: "oauth2", TokenURL: server.URL + "/token", ClientID: "autotest", ClientSecret: "{secret}"},
+		"admin":   {Type: "basic", Username: "admin", Password: "pw"},
+		"user":    {Type: "bearer", Token: "{token}"},
+		"key":     {Type: "api_key", In: "query", Name: "key", Value: "k1"},
+	})
+	r.Variables().Set("secret", "s3cret")
+	r.Variables().Set("token", "fixed")
+	expect := &apisTemplate.ExpectConfig{Status: 200}
+
+	run := func(name, auth string) *CaseResult {
+		return r.RunTestCase(TestCase{Name: name, APIConfig: &apisTemplate.TestCaseConfig{Template: "data", Auth: auth}, APIExpect: expect})
+	}
+
+	// 令牌按需获取并缓存
+	for i := 0; i < 2; i++ {
+		if result := run("oauth2", ""); result.Status != StatusPassed {
+			t.Fatalf("OAuth2 认证应通过: %s", result.Error)
+		}
+	}
+	if tokenRequests != 1 {
+		t.Errorf("令牌应被缓存, 实际请求了 %d 次", tokenRequests)
+	}
+
+	// 令牌失效后收到 401，重新获取并重试
+	r.RunTestCase(TestCase{Name: "revoke", APIConfig: &apisTemplate.TestCaseConfig{Template: "revoke"}})
+	result := run("refresh", "")
+	if result.Status != StatusPassed || tokenRequests != 2 || len(result.API) != 2 || result.API[0].StatusCode != 401 {
+		t.Errorf("401 后应刷新令牌并重试: %s, 令牌请求 %d 次, API 记录 %d 条", result.Status, tokenRequests, len(result.API))
+	}
+
+	// basic / bearer / api_key，用例中的 auth 覆盖模板
+	for _, auth := range []string{"admin", "user", "key"} {
+		if result := run(auth, auth); result.Status != StatusPassed {
+			t.Errorf("认证 '%s' 应通过: %s", auth, result.Error)
+		}
+	}
+
+	// 报告中不记录凭据
+	if got := run("admin", "admin").API[0].RequestHeaders["Authorization"]; got != "Basic ******" {
+		t.Errorf("Authorization 应被隐藏: %s", got)
+	}
+	if got := run("key", "key").API[0].URL; strings.Contains(got, "k1") || !strings.Contains(got, "key=%2A%2A%2A%2A%2A%2A") {
+		t.Errorf("查询参数中的 API Key 应被隐藏: %s", got)
+	}
+	headers := redactHeaders(map[string]string{"Cookie": "sid=1", "X-Token": "t", "Accept": "*/*"}, "x-token")
+	if headers["Cookie"] != "******" || headers["X-Token"] != "******" || headers["Accept"] != "*/*" {
+		t.Errorf("请求头隐藏错误: %v", headers)
+	}
+
+	// bearer 无法刷新，401 直接失败；未定义的认证配置报错
+	r.Variables().Set("token", "expired")
+	if result := run("expired", "user"); result.Status != StatusFailed || len(result.API) != 1 {
+		t.Errorf("bearer 令牌失效应直接失败: %s, %d", result.Status, len(result.API))
+	}
+	if result := run("missing", "nobody"); result.Status != StatusFailed || !strings.Contains(result.Error, "未定义的认证配置") {
+		t.Errorf("未定义的认证配置应失败: %s", result.Error)
+	}
+}
+
+func TestRunTestCase_Poll(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		calls++
+		status := "pending"
+		if calls >= 3 {
+			status = "done"
+		}
+		w.Write([]byte(`{"task": {"id": 7, "status": "` + status + `"}}`))
+	}))
+	defer server.Close()
+
+	templates := apisTemplate.APITemplates{
+		"push_status": {URL: server.URL, Method: "get", SaveResponse: map[string]string{"task_id": "response.task.id"}},
+	}
+	var suite TestSuite
+	if err := json.Unmarshal([]byte(`[
+		{"name": "等待下发完成", "api_config": {"template": "push_status",
+			"poll": {"interval_ms": 10, "timeout_ms": 2000, "until": {"path": "$.task.status", "op": "eq", "value": "{expected}"}}}},
+		{"name": "等待超时", "api_config": {"template": "push_status",
+			"poll": {"interval_ms": 10, "timeout_ms": 50, "until": [{"path": "$.task.status", "op": "eq", "value": "failed"}]}}}
+	]`), &suite); err != nil {
+		t.Fatalf("用例解析失败: %v", err)
+	}
+
+	r := NewRunner(nil, templates)
+	r.Variables().Set("expected", "done")
+	result := r.RunTestCase(suite[0])
+	if result.Status != StatusPassed || calls != 3 {
+		t.Fatalf("应在第 3 次请求时满足条件: %s (%s), 请求 %d 次", result.Status, result.Error, calls)
+	}
+	if len(result.API) != 1 || !strings.Contains(result.API[0].ResponseBody, "done") {
+		t.Errorf("报告中应只保留最后一次请求: %d", len(result.API))
+	}
+	if id, _ := r.Variables().Get("task_id"); id != "7" {
+		t.Errorf("轮询结束后应保存响应字段, 实际 %q", id)
+	}
+
+	result = r.RunTestCase(suite[1])
+	if result.Status != StatusFailed || !strings.Contains(result.Error, "轮询超时") || !strings.Contains(result.Error, `实际 "done"`) {
+		t.Errorf("超时应失败并给出最后一次响应: %s", result.Error)
+	}
+	if len(result.API) != 1 {
+		t.Errorf("超时后报告中应保留最后一次请求: %d", len(result.API))
+	}
+
+	// UI 步骤中的 wait_for_api
+	step := browseTemplate.TestStep{Action: "wait_for_api", API: suite[0].APIConfig}
+	if err := r.handleWaitForAPI(r.vars.resolveStep(step)); err != nil {
+		t.Errorf("wait_for_api 应通过: %v", err)
+	}
+	if err := r.handleWaitForAPI(browseTemplate.TestStep{Action: "wait_for_api", API: &apisTemplate.TestCaseConfig{Template: "push_status"}}); err == nil {
+		t.Error("wait_for_api 缺少 until 应返回错误")
+	}
+}
+
+func TestRouteMock_PatchAndValidate(t *testing.T) {
+	var doc interface{}
+	json.Unmarshal([]byte(`{"data": {"items": [{"id": 1, "status": "ok"}, {"id": 2}], "total": 2}, "a/b": 1}`), &doc)
+	ops := []browseTemplate.JSONPatchOp{
+		{Op: "test", Path: "/data/total", Value: json.RawMessage(`2`)},
+		{Op: "replace", Path: "/data/items/0/status", Value: json.RawMessage(`"error"`)},
+		{Op: "add", Path: "/data/items/-", Value: json.RawMessage(`{"id": 3}`)},
+		{Op: "remove", Path: "/data/items/1"},
+		{Op: "copy", From: "/data/total", Path: "/data/count"},
+		{Op: "move", From: "/a~1b", Path: "/flag"},
+	}
+	patched, err := applyJSONPatch(doc, ops)
+	if err != nil {
+		t.Fatalf("applyJSONPatch 出错: %v", err)
+	}
+	got, _ := json.Marshal(patched)
+	want := `{"data":{"count":2,"items":[{"id":1,"status":"error"},{"id":3}],"total":2},"flag":1}`
+	if string(got) != want {
+		t.Errorf("patch 结果错误:\n实际 %s\n期望 %s", got, want)
+	}
+
+	if _, err := applyJSONPatch(patched, []browseTemplate.JSONPatchOp{{Op: "replace", Path: "/data/missing", Value: json.RawMessage(`1`)}}); err == nil ||
+		!strings.Contains(err.Error(), "路径不存在") {
+		t.Errorf("replace 不存在的路径应返回错误: %v", err)
+	}
+	if _, err := applyJSONPatch(patched, []browseTemplate.JSONPatchOp{{Op: "test", Path: "/data/total", Value: json.RawMessage(`3`)}}); err == nil {
+		t.Error("test 不匹配时应返回错误")
+	}
+
+	invalid := []browseTemplate.RouteMockConfig{
+		{},
+		{Abort: "failed", Status: 500},
+		{Body: json.RawMessage(`{}`), BodyFile: "a.json"},
+		{Patch: []browseTemplate.JSONPatchOp{{Op: "replace", Path: "/a"}}},
+		{Patch: []browseTemplate.JSONPatchOp{{Op: "merge", Path: "/a"}}},
+		{BodyFile: filepath.Join(t.TempDir(), "missing.json")},
+	}
+	for _, mock := range invalid {
+		if err := validateRouteMock(mock); err == nil {
+			t.Errorf("无效配置应返回错误: %+v", mock)
+		}
+	}
+	if err := validateRouteMock(browseTemplate.RouteMockConfig{DelayMs: 3000}); err != nil {
+		t.Errorf("只配置延迟应有效: %v", err)
+	}
+
+	// 包含匹配的规则转换为正则时需要转义
+	re, _ := utils.CompileURLPattern("/api/users?page=1")
+	if !re.MatchString("https://example.com/api/users?page=1") || re.MatchString("https://example.com/api/usersXpage=1") {
+		t.Errorf("包含匹配转换错误: %s", re)
+	}
+
+	// 变量值中的引号在 body / patch 中需要转义
+	vars := NewVariables()
+	vars.Set("name", `say "hi"`)
+	step := vars.resolveStep(browseTemplate.TestStep{Action: "route_mock", Mock: &browseTemplate.RouteMockConfig{
+		Body:  json.RawMessage(`{"name": "{name}", "id": 12345678901234567890}`),
+		Patch: []browseTemplate.JSONPatchOp{{Op: "replace", Path: "/name", Value: json.RawMessage(`"{name}"`)}},
+	}})
+	if got := string(step.Mock.Body); got != `{"id":12345678901234567890,"name":"say \"hi\""}` {
+		t.Errorf("body 变量替换错误: %s", got)
+	}
+	if got := string(step.Mock.Patch[0].Value); got != `"say \"hi\""` {
+		t.Errorf("patch 变量替换错误: %s", got)
+	}
+}
+
+func TestSignalLog_FailOnErrors(t *testing.T) {
+	log, err := newSignalLog(browseTemplate.BrowserErrorsConfig{
+		FailOnPageError:   true,
+		FailOnServerError: true,
+		Allow:             []string{"**/api/heartbeat", "ResizeObserver loop"},
+	})
+	if err != nil {
+		t.Fatalf("newSignalLog 出错: %v", err)
+	}
+
+	log.setStep(1)
+	log.add(&BrowserEvent{Type: EventConsole, Level: "error", Message: "Failed to load resource"}, false)
+	log.add(&BrowserEvent{Type: EventResponse, Method: "GET", URL: "https://dev/api/list", Status: 404}, false)
+	log.add(&BrowserEvent{Type: EventPageError, Message: "ResizeObserver loop limit exceeded"}, true)
+	log.add(&BrowserEvent{Type: EventResponse, Method: "GET", URL: "https://dev/api/heartbeat", Status: 502}, true)
+	if err := log.takeFailure(); err != nil {
+		t.Errorf("非致命或在 allow 列表中的信号不应导致失败: %v", err)
+	}
+
+	log.setStep(2)
+	log.add(&BrowserEvent{Type: EventPageError, Message: "TypeError: x is undefined"}, true)
+	log.add(&BrowserEvent{Type: EventResponse, Method: "POST", URL: "https://dev/api/save", Status: 500}, true)
+	err = log.takeFailure()
+	if err == nil || !strings.Contains(err.Error(), "未捕获的异常: TypeError") || !strings.Contains(err.Error(), "共 2 个") {
+		t.Errorf("未捕获异常与 5xx 应导致失败: %v", err)
+	}
+	if err := log.takeFailure(); err != nil {
+		t.Errorf("已报告的信号不应重复报告: %v", err)
+	}
+
+	events := log.stop()
+	if len(events) != 6 || events[5].Step != 2 || !events[5].Fatal || events[3].Fatal {
+		t.Errorf("信号记录错误: %+v", events)
+	}
+
+	// 信号写入 JUnit 报告的 system-err
+	suite := &SuiteResult{Name: "ui.json", Cases: []*CaseResult{{Name: "列表", Status: StatusPassed, Events: events}}}
+	path := filepath.Join(t.TempDir(), "junit.xml")
+	if err := WriteJUnitReport(path, suite); err != nil {
+		t.Fatalf("WriteJUnitReport 出错: %v", err)
+	}
+	content, _ := os.ReadFile(path)
+	if !strings.Contains(string(content), "[step 2] 响应 500: POST https://dev/api/save</system-err>") {
+		t.Errorf("JUnit 报告缺少页面错误信号:\n%s", content)
+	}
+
+	if _, err := newSignalLog(browseTemplate.BrowserErrorsConfig{Allow: []string{"/(/"}}); err == nil {
+		t.Error("无效的 allow 规则应返回错误")
+	}
+}
+
+func TestPageConfigAndFrameVariables(t *testing.T) {
+	valid := []browseTemplate.PageConfig{{Index: 2}, {Title: "报表"}, {URL: "**/report/**"}, {Popup: true}}
+	for _, config := range valid {
+		if err := validatePageConfig(config); err != nil {
+			t.Errorf("配置应有效: %+v, %v", config, err)
+		}
+	}
+	invalid := []browseTemplate.PageConfig{{}, {Index: 1, Popup: true}, {Title: "a", URL: "b"}, {Index: -1}}
+	for _, config := range invalid {
+		if err := validatePageConfig(config); err == nil {
+			t.Errorf("无效配置应返回错误: %+v", config)
+		}
+	}
+
+	step := browseTemplate.TestStep{
+		Action: "switch_page",
+		Page:   &browseTemplate.PageConfig{Title: "{report} 详情"},
+	}
+	if got := describeStep(step); got != "page title={report} 详情" {
+		t.Errorf("步骤描述错误: %s", got)
+	}
+
+	vars := NewVariables()
+	vars.Set("report", "月报")
+	vars.Set("frame", "legacy")
+	step.Selector = &utils.SelectorConfig{Type: "field", Value: "名称", Scope: "frame", Frame: &utils.FrameConfig{Name: "{frame}"}}
+	resolved := vars.resolveStep(step)
+	if resolved.Page.Title != "月报 详情" || resolved.Selector.Frame.Name != "legacy" {
+		t.Errorf("变量替换错误: %+v, %+v", resolved.Page, resolved.Selector.Frame)
+	}
+	if step.Page.Title != "{report} 详情" || step.Selector.Frame.Name != "{frame}" {
+		t.Error("resolveStep 不应修改原始步骤")
+	}
+}
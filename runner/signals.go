@@ -0,0 +1,196 @@
+package runner
+
+import (
+	browseTemplate "autotest/browse-template"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// maxBrowserEvents 每个用例最多记录的页面错误信号数量，避免嘈杂的页面撑大报告
+const maxBrowserEvents = 200
+
+// signalLog 记录 UI 用例执行期间浏览器上下文中所有页面（包括新打开的标签页）的错误信号
+// 按 browser_errors 配置判断信号是否导致用例失败，失败在当前步骤结束时报告
+type signalLog struct {
+	mu      sync.Mutex
+	context playwright.BrowserContext
+	allow   []func(string) bool
+	step    int
+	events  []*BrowserEvent
+	dropped int
+	pending []*BrowserEvent // 尚未报告的导致失败的信号
+
+	onConsole       func(playwright.ConsoleMessage)
+	onWebError      func(playwright.WebError)
+	onRequestFailed func(playwright.Request)
+	onResponse      func(playwright.Response)
+}
+
+// newSignalLog 按配置创建信号记录
+func newSignalLog(config browseTemplate.BrowserErrorsConfig) (*signalLog, error) {
+	log := &signalLog{}
+	for _, pattern := range config.Allow {
+		match, err := newURLMatcher(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("browser_errors.allow 无效: %v", err)
+		}
+		log.allow = append(log.allow, match)
+	}
+	return log, nil
+}
+
+// watchSignals 开始记录浏览器上下文中的错误信号
+func watchSignals(context playwright.BrowserContext, config browseTemplate.BrowserErrorsConfig) (*signalLog, error) {
+	log, err := newSignalLog(config)
+	if err != nil {
+		return nil, err
+	}
+	log.context = context
+
+	log.onConsole = func(msg playwright.ConsoleMessage) {
+		level := msg.Type()
+		if level != "error" && level != "warning" {
+			return
+		}
+		event := &BrowserEvent{Type: EventConsole, Level: level, Message: msg.Text()}
+		if location := msg.Location(); location != nil {
+			event.URL = location.URL
+		}
+		log.add(event, level == "error" && config.FailOnConsoleError)
+	}
+	log.onWebError = func(webErr playwright.WebError) {
+		event := &BrowserEvent{Type: EventPageError, Message: fmt.Sprint(webErr.Error())}
+		if page := webErr.Page(); page != nil {
+			event.URL = page.URL()
+		}
+		log.add(event, config.FailOnPageError)
+	}
+	log.onRequestFailed = func(req playwright.Request) {
+		reason := ""
+		if failure := req.Failure(); failure != nil {
+			reason = failure.Error()
+		}
+		event := &BrowserEvent{Type: EventRequestFailed, Method: req.Method(), URL: req.URL(), Message: reason}
+		log.add(event, config.FailOnRequestFailed && !isCancelledRequest(reason))
+	}
+	log.onResponse = func(resp playwright.Response) {
+		if resp.Status() < 400 {
+			return
+		}
+		event := &BrowserEvent{Type: EventResponse, Method: resp.Request().Method(), URL: resp.URL(), Status: resp.Status(), Message: resp.StatusText()}
+		log.add(event, resp.Status() >= 500 && config.FailOnServerError)
+	}
+
+	context.OnConsole(log.onConsole)
+	context.OnWebError(log.onWebError)
+	context.OnRequestFailed(log.onRequestFailed)
+	context.OnResponse(log.onResponse)
+	return log, nil
+}
+
+// isCancelledRequest 页面跳转、重复点击等取消的请求不视为失败
+func isCancelledRequest(reason string) bool {
+	return strings.Contains(reason, "ERR_ABORTED") || strings.Contains(reason, "NS_BINDING_ABORTED") ||
+		strings.Contains(reason, "cancelled")
+}
+
+// add 记录一个信号，fatal 且不在 allow 列表中时导致用例失败
+func (l *signalLog) add(event *BrowserEvent, fatal bool) {
+	event.Time = time.Now()
+	event.Fatal = fatal && !l.allowed(event)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	event.Step = l.step
+	if event.Fatal {
+		l.pending = append(l.pending, event)
+	}
+	// 导致失败的信号总是保留
+	if len(l.events) >= maxBrowserEvents && !event.Fatal {
+		l.dropped++
+		return
+	}
+	l.events = append(l.events, event)
+}
+
+func (l *signalLog) allowed(event *BrowserEvent) bool {
+	for _, match := range l.allow {
+		if (event.URL != "" && match(event.URL)) || (event.Message != "" && match(event.Message)) {
+			return true
+		}
+	}
+	return false
+}
+
+// setStep 记录当前执行的步骤序号
+func (l *signalLog) setStep(step int) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.step = step
+}
+
+// takeFailure 返回尚未报告的导致失败的信号，没有时返回 nil
+func (l *signalLog) takeFailure() error {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.pending) == 0 {
+		return nil
+	}
+	first := l.pending[0]
+	count := len(l.pending)
+	l.pending = nil
+	if count == 1 {
+		return fmt.Errorf("页面错误: %s", describeEvent(first))
+	}
+	return fmt.Errorf("页面错误: %s（共 %d 个）", describeEvent(first), count)
+}
+
+// stop 停止记录并返回记录的信号
+func (l *signalLog) stop() []*BrowserEvent {
+	if l == nil {
+		return nil
+	}
+	if l.context != nil {
+		l.context.RemoveListener("console", l.onConsole)
+		l.context.RemoveListener("weberror", l.onWebError)
+		l.context.RemoveListener("requestfailed", l.onRequestFailed)
+		l.context.RemoveListener("response", l.onResponse)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.dropped > 0 {
+		l.events = append(l.events, &BrowserEvent{
+			Type:    EventConsole,
+			Level:   "warning",
+			Message: fmt.Sprintf("信号过多，另有 %d 个未记录", l.dropped),
+			Step:    l.step,
+			Time:    time.Now(),
+		})
+	}
+	return l.events
+}
+
+// describeEvent 生成信号的简短描述，用于错误信息与报告
+func describeEvent(event *BrowserEvent) string {
+	switch event.Type {
+	case EventPageError:
+		return "未捕获的异常: " + event.Message
+	case EventResponse:
+		return fmt.Sprintf("响应 %d: %s %s", event.Status, event.Method, event.URL)
+	case EventRequestFailed:
+		return fmt.Sprintf("请求失败: %s %s (%s)", event.Method, event.URL, event.Message)
+	default:
+		return fmt.Sprintf("console.%s: %s", event.Level, event.Message)
+	}
+}
@@ -0,0 +1,52 @@
+package runner
+
+import (
+	browseTemplate "autotest/browse-template"
+	"fmt"
+)
+
+// startTrace 按 trace 配置开始录制当前 UI 用例的 trace，录制失败只警告不影响用例执行
+func (r *Runner) startTrace(testCase TestCase) {
+	r.tracing = false
+	mode := r.config.Trace
+	if mode == "" || mode == browseTemplate.TraceOff {
+		return
+	}
+	if err := browseTemplate.StartTrace(r.page.Context(), testCase.Name); err != nil {
+		fmt.Fprintf(r.out, "  ⚠️  %v\n", err)
+		return
+	}
+	r.tracing = true
+}
+
+// stopTrace 停止录制 trace，trace: on 或用例失败时保存，返回保存的文件路径
+func (r *Runner) stopTrace(testCase TestCase, failed bool) string {
+	if !r.tracing {
+		return ""
+	}
+	r.tracing = false
+	path := ""
+	if failed || r.config.Trace == browseTemplate.TraceOn {
+		path = browseTemplate.TracePath(testCase.Name)
+	}
+	if err := browseTemplate.StopTrace(r.page.Context(), path); err != nil {
+		fmt.Fprintf(r.out, "  ⚠️  %v\n", err)
+		return ""
+	}
+	if path != "" {
+		fmt.Fprintf(r.out, "  🔬 已保存 trace: %s（查看: npx playwright show-trace %s）\n", path, path)
+	}
+	return path
+}
+
+// traceStep 在 trace 中为步骤创建分组，返回结束分组的函数
+func (r *Runner) traceStep(index int, action string) func() {
+	if !r.tracing {
+		return func() {}
+	}
+	tracing := r.page.Context().Tracing()
+	if err := tracing.Group(fmt.Sprintf("[%d] %s", index, action)); err != nil {
+		return func() {}
+	}
+	return func() { _ = tracing.GroupEnd() }
+}
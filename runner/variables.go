@@ -0,0 +1,342 @@
+package runner
+
+import (
+	apisTemplate "autotest/apis-template"
+	browseTemplate "autotest/browse-template"
+	"autotest/browse-template/utils"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Variables 运行期变量存储
+// 由 API 模板的 save_response 写入，在后续用例中以 {name} 占位符的形式引用
+type Variables struct {
+	mu     sync.RWMutex
+	values map[string]string
+	raw    map[string]json.RawMessage // 非字符串变量的 JSON 原文，用于按类型替换
+}
+
+// NewVariables 创建空的变量存储
+func NewVariables() *Variables {
+	return &Variables{values: make(map[string]string), raw: make(map[string]json.RawMessage)}
+}
+
+// Set 设置字符串变量
+func (v *Variables) Set(name, value string) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.values[name] = value
+	delete(v.raw, name)
+}
+
+// SetValue 设置任意 JSON 类型的变量
+// 非字符串值在 data / query 中独占整个字符串时按原类型替换，其余位置使用其 JSON 文本；
+// null 的文本与 stringifyValue 一致为空字符串，独占整个字符串时仍替换为 null
+func (v *Variables) SetValue(name string, value interface{}) {
+	str, ok := value.(string)
+	if ok {
+		v.Set(name, str)
+		return
+	}
+	raw, err := json.Marshal(value)
+	if err != nil {
+		v.Set(name, fmt.Sprintf("%v", value))
+		return
+	}
+
+	text := string(raw)
+	if value == nil {
+		text = ""
+	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.values[name] = text
+	v.raw[name] = raw
+}
+
+// Get 获取变量
+func (v *Variables) Get(name string) (string, bool) {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	value, ok := v.values[name]
+	return value, ok
+}
+
+// Params 将所有变量转换为 GenerateRequest 可用的参数列表（Key 为 "{name}"）
+// 按变量名排序，保证替换顺序稳定
+func (v *Variables) Params() []apisTemplate.Param {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	names := make([]string, 0, len(v.values))
+	for name := range v.values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	params := make([]apisTemplate.Param, 0, len(names))
+	for _, name := range names {
+		params = append(params, apisTemplate.Param{Key: "{" + name + "}", Value: v.values[name], Raw: v.raw[name]})
+	}
+	return params
+}
+
+// Replace 替换字符串中出现的 {name} 占位符，未定义的占位符保持原样
+func (v *Variables) Replace(str string) string {
+	if !strings.Contains(str, "{") {
+		return str
+	}
+	result := str
+	for _, p := range v.Params() {
+		result = strings.ReplaceAll(result, p.Key, p.Value)
+	}
+	return result
+}
+
+// resolveParams 合并用例参数与运行期变量
+// 用例参数的 Value 中也可以引用变量（非字符串参数保持原值）；用例参数排在前面，同名时优先生效
+func (v *Variables) resolveParams(params []apisTemplate.Param) []apisTemplate.Param {
+	resolved := make([]apisTemplate.Param, 0, len(params))
+	for _, p := range params {
+		if p.Raw != nil {
+			resolved = append(resolved, p)
+			continue
+		}
+		resolved = append(resolved, apisTemplate.Param{Key: p.Key, Value: v.Replace(p.Value)})
+	}
+	return append(resolved, v.Params()...)
+}
+
+// resolveStep 返回替换了变量占位符的步骤副本，不修改原始步骤
+func (v *Variables) resolveStep(step browseTemplate.TestStep) browseTemplate.TestStep {
+	step.URL = v.Replace(step.URL)
+	step.Text = v.Replace(step.Text)
+	step.MenuPath = v.Replace(step.MenuPath)
+
+	if len(step.Options) > 0 {
+		options := make([]string, len(step.Options))
+		for i, opt := range step.Options {
+			options[i] = v.Replace(opt)
+		}
+		step.Options = options
+	}
+
+	step.Selector = v.resolveSelectorPtr(step.Selector)
+	if len(step.Selectors) > 0 {
+		selectors := make([]utils.SelectorConfig, len(step.Selectors))
+		for i, sel := range step.Selectors {
+			selectors[i] = v.resolveSelector(sel)
+		}
+		step.Selectors = selectors
+	}
+
+	if step.Expect != nil {
+		expect := *step.Expect
+		expect.Value = v.Replace(expect.Value)
+		expect.Text = v.Replace(expect.Text)
+		step.Expect = &expect
+	}
+
+	if step.Table != nil {
+		table := *step.Table
+		table.Selector = v.resolveSelector(table.Selector)
+		table.Value = v.Replace(table.Value)
+		if table.Row != nil {
+			row := *table.Row
+			row.Value = v.Replace(row.Value)
+			table.Row = &row
+		}
+		if table.Column != nil {
+			column := *table.Column
+			column.Value = v.Replace(column.Value)
+			table.Column = &column
+		}
+		step.Table = &table
+	}
+
+	if step.API != nil {
+		api := v.resolveAPIConfig(*step.API)
+		step.API = &api
+	}
+
+	if step.Mock != nil {
+		mock := *step.Mock
+		mock.BodyFile = v.Replace(mock.BodyFile)
+		if len(mock.Body) > 0 {
+			mock.Body = v.resolveRawJSON(mock.Body)
+		}
+		if len(mock.Headers) > 0 {
+			headers := make(map[string]string, len(mock.Headers))
+			for key, value := range mock.Headers {
+				headers[key] = v.Replace(value)
+			}
+			mock.Headers = headers
+		}
+		if len(mock.Patch) > 0 {
+			patch := make([]browseTemplate.JSONPatchOp, len(mock.Patch))
+			for i, op := range mock.Patch {
+				op.Path = v.Replace(op.Path)
+				if len(op.Value) > 0 {
+					op.Value = v.resolveRawJSON(op.Value)
+				}
+				patch[i] = op
+			}
+			mock.Patch = patch
+		}
+		step.Mock = &mock
+	}
+
+	if step.Page != nil {
+		page := *step.Page
+		page.Title = v.Replace(page.Title)
+		page.URL = v.Replace(page.URL)
+		step.Page = &page
+	}
+
+	if step.Search != nil {
+		search := *step.Search
+		inputs := make([]browseTemplate.SearchInput, len(search.Inputs))
+		for i, input := range search.Inputs {
+			inputs[i] = browseTemplate.SearchInput{
+				Selector: v.resolveSelectorPtr(input.Selector),
+				Text:     v.Replace(input.Text),
+			}
+		}
+		search.Inputs = inputs
+		search.Button = v.resolveSelectorPtr(search.Button)
+		step.Search = &search
+	}
+
+	return step
+}
+
+// resolveAPIConfig 返回合并了变量参数、替换了轮询条件中占位符的 API 调用配置副本
+func (v *Variables) resolveAPIConfig(config apisTemplate.TestCaseConfig) apisTemplate.TestCaseConfig {
+	config.Params = v.resolveParams(config.Params)
+	if config.Poll != nil {
+		poll := *config.Poll
+		poll.Until = v.resolveExpect(poll.Until)
+		config.Poll = &poll
+	}
+	return config
+}
+
+// resolveExpect 返回替换了变量占位符的 API 期望副本（headers、raw、body 与 assertions），不修改原始配置
+func (v *Variables) resolveExpect(expect *apisTemplate.ExpectConfig) *apisTemplate.ExpectConfig {
+	if expect == nil {
+		return nil
+	}
+	resolved := *expect
+	resolved.RawContains = v.Replace(expect.RawContains)
+	resolved.RawRegex = v.Replace(expect.RawRegex)
+	if len(expect.Headers) > 0 {
+		headers := make(map[string]apisTemplate.HeaderExpect, len(expect.Headers))
+		for name, header := range expect.Headers {
+			headers[name] = apisTemplate.HeaderExpect{
+				Equals:   v.Replace(header.Equals),
+				Contains: v.Replace(header.Contains),
+				Regex:    v.Replace(header.Regex),
+			}
+		}
+		resolved.Headers = headers
+	}
+	if expect.Body != nil {
+		if body, ok := v.resolveValue(expect.Body).(map[string]interface{}); ok {
+			resolved.Body = body
+		}
+	}
+	if len(expect.Assertions) > 0 {
+		assertions := make([]apisTemplate.Assertion, len(expect.Assertions))
+		for i, assertion := range expect.Assertions {
+			assertion.Path = v.Replace(assertion.Path)
+			assertion.Value = v.resolveValue(assertion.Value)
+			assertions[i] = assertion
+		}
+		resolved.Assertions = assertions
+	}
+	return &resolved
+}
+
+// resolveValue 递归替换 JSON 值中字符串的占位符
+// 字符串恰好是一个非字符串变量的占位符时（如 "{item_id}"），按变量的原始类型替换
+func (v *Variables) resolveValue(input interface{}) interface{} {
+	switch val := input.(type) {
+	case string:
+		if typed, ok := v.typedValue(val); ok {
+			return typed
+		}
+		return v.Replace(val)
+	case map[string]interface{}:
+		newMap := make(map[string]interface{}, len(val))
+		for k, item := range val {
+			newMap[k] = v.resolveValue(item)
+		}
+		return newMap
+	case []interface{}:
+		newSlice := make([]interface{}, len(val))
+		for i, item := range val {
+			newSlice[i] = v.resolveValue(item)
+		}
+		return newSlice
+	default:
+		return val
+	}
+}
+
+// typedValue 字符串恰好是一个非字符串变量的占位符时，返回变量的原始类型值
+func (v *Variables) typedValue(str string) (interface{}, bool) {
+	if !strings.HasPrefix(str, "{") || !strings.HasSuffix(str, "}") {
+		return nil, false
+	}
+	v.mu.RLock()
+	raw, ok := v.raw[str[1:len(str)-1]]
+	v.mu.RUnlock()
+	if !ok {
+		return nil, false
+	}
+	var typed interface{}
+	if err := json.Unmarshal(raw, &typed); err != nil {
+		return nil, false
+	}
+	return typed, true
+}
+
+// resolveRawJSON 解码 JSON 后替换其中字符串的占位符再重新编码，变量值中的引号等字符会被正确转义
+// 不是合法 JSON 时原样返回，由后续校验给出错误
+func (v *Variables) resolveRawJSON(raw json.RawMessage) json.RawMessage {
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return raw
+	}
+	resolved, err := json.Marshal(v.resolveValue(value))
+	if err != nil {
+		return raw
+	}
+	return resolved
+}
+
+func (v *Variables) resolveSelector(selector utils.SelectorConfig) utils.SelectorConfig {
+	selector.Value = v.Replace(selector.Value)
+	if selector.Frame != nil {
+		frame := *selector.Frame
+		frame.Name = v.Replace(frame.Name)
+		frame.URL = v.Replace(frame.URL)
+		frame.Selector = v.Replace(frame.Selector)
+		selector.Frame = &frame
+	}
+	return selector
+}
+
+func (v *Variables) resolveSelectorPtr(selector *utils.SelectorConfig) *utils.SelectorConfig {
+	if selector == nil {
+		return nil
+	}
+	resolved := v.resolveSelector(*selector)
+	return &resolved
+}
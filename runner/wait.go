@@ -0,0 +1,216 @@
+package runner
+
+import (
+	browseTemplate "autotest/browse-template"
+	"autotest/browse-template/utils"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// SetBrowserConfig 设置浏览器配置，等待类操作的超时时间和步骤后的自动等待取自该配置
+func (r *Runner) SetBrowserConfig(config *browseTemplate.Config) {
+	r.config = config
+}
+
+// waitTimeout 返回步骤的等待超时（毫秒），步骤未指定时使用配置中的 timeout
+func (r *Runner) waitTimeout(step browseTemplate.TestStep) float64 {
+	if step.Timeout > 0 {
+		return float64(step.Timeout)
+	}
+	return float64(r.config.Timeout)
+}
+
+// settle 步骤执行后等待页面稳定，取代固定时长的 sleep
+func (r *Runner) settle() error {
+	autoWait := r.config.AutoWait
+	if autoWait.Disabled {
+		return nil
+	}
+	return utils.WaitForPageSettled(r.page, utils.WaitOptions{
+		Timeout:      float64(r.config.Timeout),
+		NetworkIdle:  float64(autoWait.NetworkIdle),
+		LoadingMasks: autoWait.LoadingMasks,
+	})
+}
+
+// isWaitAction 是否为显式等待类步骤
+func isWaitAction(action string) bool {
+	return strings.HasPrefix(action, "wait_")
+}
+
+// responseLog 记录用例执行期间浏览器上下文中所有页面收到的响应，供 wait_for_response 查询
+// 响应往往由上一个步骤（如点击）触发，可能在等待步骤开始前就已返回，因此需要提前记录
+type responseLog struct {
+	mu      sync.Mutex
+	context playwright.BrowserContext
+	handler func(playwright.Response)
+	since   time.Time
+	entries []responseEntry
+}
+
+type responseEntry struct {
+	url    string
+	status int
+	at     time.Time
+}
+
+// watchResponses 开始记录页面响应
+func watchResponses(context playwright.BrowserContext) *responseLog {
+	log := &responseLog{context: context, since: time.Now()}
+	log.handler = func(resp playwright.Response) {
+		log.mu.Lock()
+		defer log.mu.Unlock()
+		log.entries = append(log.entries, responseEntry{url: resp.URL(), status: resp.Status(), at: time.Now()})
+	}
+	context.OnResponse(log.handler)
+	return log
+}
+
+// mark 记录一个操作步骤的开始时间，之后的 wait_for_response 只匹配此时间之后收到的响应
+func (l *responseLog) mark() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.since = time.Now()
+}
+
+// find 查找已收到的匹配响应，status 为 0 时不限状态码
+// 未找到时返回最近一个 URL 匹配但状态码不符的响应状态码（没有则为 0），便于给出失败原因
+func (l *responseLog) find(match func(string) bool, status int) (bool, int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	lastStatus := 0
+	for _, entry := range l.entries {
+		if entry.at.Before(l.since) || !match(entry.url) {
+			continue
+		}
+		if status == 0 || entry.status == status {
+			return true, entry.status
+		}
+		lastStatus = entry.status
+	}
+	return false, lastStatus
+}
+
+func (l *responseLog) stop() {
+	l.context.RemoveListener("response", l.handler)
+}
+
+// newURLMatcher 解析 URL 匹配规则
+// "/.../" 形式为正则表达式；包含 * 时为通配符（** 匹配任意字符，* 匹配除 / 以外的字符）；否则为包含匹配
+func newURLMatcher(pattern string) (func(string) bool, error) {
+	re, err := utils.CompileURLPattern(pattern)
+	if err != nil {
+		return nil, err
+	}
+	return re.MatchString, nil
+}
+
+// parseWaitState 将 wait_for 的 state 转换为 Playwright 的等待状态
+func parseWaitState(state string) (*playwright.WaitForSelectorState, error) {
+	switch state {
+	case "", "visible":
+		return playwright.WaitForSelectorStateVisible, nil
+	case "hidden":
+		return playwright.WaitForSelectorStateHidden, nil
+	case "attached":
+		return playwright.WaitForSelectorStateAttached, nil
+	case "detached":
+		return playwright.WaitForSelectorStateDetached, nil
+	default:
+		return nil, fmt.Errorf("未知的等待状态: %s", state)
+	}
+}
+
+// handleWaitFor 等待元素达到指定状态
+func (r *Runner) handleWaitFor(step browseTemplate.TestStep) error {
+	if step.Selector == nil {
+		return errors.New("wait_for action 需要提供 selector")
+	}
+	state, err := parseWaitState(step.State)
+	if err != nil {
+		return err
+	}
+
+	var selector string
+	switch step.Selector.Type {
+	case "css", "xpath":
+		selector = step.Selector.Value
+	case "id":
+		selector = "#" + step.Selector.Value
+	case "text", "":
+		selector = "text=" + step.Selector.Value
+	default:
+		return fmt.Errorf("wait_for 不支持的选择器类型: %s", step.Selector.Type)
+	}
+
+	locator, err := utils.ScopeLocator(r.page, *step.Selector, selector)
+	if err != nil {
+		return err
+	}
+	err = locator.First().WaitFor(playwright.LocatorWaitForOptions{
+		State:   state,
+		Timeout: playwright.Float(r.waitTimeout(step)),
+	})
+	if err != nil {
+		return fmt.Errorf("等待元素 '%s' 状态 %s 超时: %v", step.Selector.Value, *state, err)
+	}
+	return nil
+}
+
+// handleWaitForURL 等待页面 URL 匹配
+func (r *Runner) handleWaitForURL(step browseTemplate.TestStep) error {
+	if step.URL == "" {
+		return errors.New("wait_for_url action 需要提供 url")
+	}
+	match, err := newURLMatcher(step.URL)
+	if err != nil {
+		return err
+	}
+	err = r.page.WaitForURL(match, playwright.PageWaitForURLOptions{
+		Timeout: playwright.Float(r.waitTimeout(step)),
+	})
+	if err != nil {
+		return fmt.Errorf("等待 URL 匹配 '%s' 超时，当前 URL: %s", step.URL, r.page.URL())
+	}
+	return nil
+}
+
+// handleWaitForResponse 等待匹配的接口响应，包括上一个操作步骤开始后已经收到的响应
+func (r *Runner) handleWaitForResponse(step browseTemplate.TestStep) error {
+	if step.URL == "" {
+		return errors.New("wait_for_response action 需要提供 url")
+	}
+	match, err := newURLMatcher(step.URL)
+	if err != nil {
+		return err
+	}
+
+	deadline := time.Now().Add(time.Duration(r.waitTimeout(step)) * time.Millisecond)
+	for {
+		found, status := r.responses.find(match, step.Status)
+		if found {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			if status != 0 {
+				return fmt.Errorf("响应状态码不匹配: 期望 %d, 实际 %d (%s)", step.Status, status, step.URL)
+			}
+			return fmt.Errorf("等待响应 '%s' 超时", step.URL)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+// handleWaitMs 固定等待指定毫秒数（仅在没有可等待的条件时使用）
+func (r *Runner) handleWaitMs(step browseTemplate.TestStep) error {
+	if step.Ms <= 0 {
+		return errors.New("wait_ms action 需要提供大于 0 的 ms")
+	}
+	time.Sleep(time.Duration(step.Ms) * time.Millisecond)
+	return nil
+}
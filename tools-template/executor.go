@@ -0,0 +1,70 @@
+package toolsTemplate
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"time"
+)
+
+// DefaultTimeout 命令默认超时时间
+const DefaultTimeout = 30 * time.Second
+
+// ToolResult 封装命令执行后的结果
+type ToolResult struct {
+	Command  string        // 实际执行的命令
+	ExitCode int           // 退出码
+	Stdout   string        // 标准输出
+	Stderr   string        // 标准错误
+	Duration time.Duration // 执行耗时
+}
+
+// ExecuteCommand 通过系统 shell 执行命令
+// 超时或无法启动时返回错误；命令正常结束但退出码非 0 不视为错误，由断言负责判断
+func ExecuteCommand(command string, timeout time.Duration) (*ToolResult, error) {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+
+	// 超时后子进程可能仍占用输出管道，限制等待时间避免卡死
+	cmd.WaitDelay = time.Second
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	start := time.Now()
+	err := cmd.Run()
+	result := &ToolResult{
+		Command:  command,
+		Stdout:   stdout.String(),
+		Stderr:   stderr.String(),
+		Duration: time.Since(start),
+	}
+
+	if ctx.Err() == context.DeadlineExceeded {
+		result.ExitCode = -1
+		return result, fmt.Errorf("命令执行超时 (%s): %s", timeout, command)
+	}
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			result.ExitCode = exitErr.ExitCode()
+			return result, nil
+		}
+		return result, fmt.Errorf("命令启动失败: %v", err)
+	}
+	return result, nil
+}
@@ -0,0 +1,60 @@
+package toolsTemplate
+
+import (
+	apisTemplate "autotest/apis-template"
+	"fmt"
+	"regexp"
+	"runtime"
+	"strings"
+)
+
+// GenerateCommand 根据工具模板和参数列表生成最终执行的模板副本
+// 占位符替换规则与 apisTemplate.GenerateRequest 一致；命令中的替换值会按 shell 规则加引号，
+// 避免参数中的空格、引号、; 等字符改变命令结构。替换完成后仍存在未解析的占位符时返回错误
+func GenerateCommand(template ToolTemplate, params []apisTemplate.Param) (ToolTemplate, error) {
+	newTmpl := template
+	newTmpl.Command = apisTemplate.ReplaceEscaped(template.Command, params, shellQuote)
+	newTmpl.ExpectedOutput = apisTemplate.ReplaceString(template.ExpectedOutput, params)
+	if missing := apisTemplate.FindPlaceholders(newTmpl.Command, newTmpl.ExpectedOutput); len(missing) > 0 {
+		return newTmpl, fmt.Errorf("存在未解析的占位符: %s", strings.Join(missing, ", "))
+	}
+
+	expect, err := ResolveExpect(template.Expect, params)
+	if err != nil {
+		return newTmpl, err
+	}
+	newTmpl.Expect = expect
+	return newTmpl, nil
+}
+
+// ResolveExpect 返回替换了占位符的断言副本，存在未解析的占位符时返回错误
+func ResolveExpect(expect *ToolExpect, params []apisTemplate.Param) (*ToolExpect, error) {
+	if expect == nil {
+		return nil, nil
+	}
+	resolved := *expect
+	resolved.StdoutContains = apisTemplate.ReplaceString(expect.StdoutContains, params)
+	resolved.StderrContains = apisTemplate.ReplaceString(expect.StderrContains, params)
+	resolved.StdoutRegex = apisTemplate.ReplaceString(expect.StdoutRegex, params)
+	resolved.StderrRegex = apisTemplate.ReplaceString(expect.StderrRegex, params)
+	missing := apisTemplate.FindPlaceholders(resolved.StdoutContains, resolved.StderrContains, resolved.StdoutRegex, resolved.StderrRegex)
+	if len(missing) > 0 {
+		return &resolved, fmt.Errorf("断言中存在未解析的占位符: %s", strings.Join(missing, ", "))
+	}
+	return &resolved, nil
+}
+
+// shellSafePattern 不需要加引号的字符（IP、主机名、路径、数字等）
+var shellSafePattern = regexp.MustCompile(`^[A-Za-z0-9_./:@%+=,\-]+$`)
+
+// shellQuote 按执行命令的 shell 为替换值加引号
+// sh 使用单引号（值中的单引号写作 '\''），cmd 使用双引号（值中的双引号写作 ""）
+func shellQuote(value string) string {
+	if shellSafePattern.MatchString(value) {
+		return value
+	}
+	if runtime.GOOS == "windows" {
+		return `"` + strings.ReplaceAll(value, `"`, `""`) + `"`
+	}
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
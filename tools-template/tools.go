@@ -0,0 +1,132 @@
+package toolsTemplate
+
+import (
+	apisTemplate "autotest/apis-template"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// ToolTemplate 定义单个系统工具命令模板
+type ToolTemplate struct {
+	Command        string      `json:"command"`                   // 命令行，支持 {param} 占位符
+	ExpectedOutput string      `json:"expected_output,omitempty"` // 标准输出中应包含的内容
+	Expect         *ToolExpect `json:"excepted,omitempty"`        // 模板级默认断言（用例中的 expect 优先）
+	Timeout        int         `json:"timeout,omitempty"`         // 超时时间（毫秒），0 表示使用默认值
+}
+
+// ToolTemplates 定义所有工具模板的集合
+type ToolTemplates map[string]ToolTemplate
+
+// ToolExpect 定义命令执行结果的断言
+type ToolExpect struct {
+	ExitCode       *int   `json:"exit_code,omitempty"`       // 期望退出码（未设置时默认为 0）
+	StdoutContains string `json:"stdout_contains,omitempty"` // 标准输出包含
+	StderrContains string `json:"stderr_contains,omitempty"` // 标准错误包含
+	StdoutRegex    string `json:"stdout_regex,omitempty"`    // 标准输出正则匹配
+	StderrRegex    string `json:"stderr_regex,omitempty"`    // 标准错误正则匹配
+	LineCount      *int   `json:"line_count,omitempty"`      // 标准输出行数（精确）
+	MinLines       *int   `json:"min_lines,omitempty"`       // 标准输出最少行数
+	MaxLines       *int   `json:"max_lines,omitempty"`       // 标准输出最多行数
+}
+
+// TestCaseConfig 用例中的 tool_config 配置
+type TestCaseConfig struct {
+	Template string               `json:"template"`
+	Params   []apisTemplate.Param `json:"params"`
+	Timeout  int                  `json:"timeout,omitempty"` // 超时时间（毫秒），覆盖模板中的配置
+	Expect   *ToolExpect          `json:"expect,omitempty"`  // 断言配置，覆盖模板中的 excepted
+}
+
+// LoadToolTemplates 从指定的 JSON 文件加载所有工具模板
+func LoadToolTemplates(filePath string) (ToolTemplates, error) {
+	if _, err := os.Stat(filePath); os.IsNotExist(err) {
+		return nil, fmt.Errorf("没有工具模板文件")
+	}
+
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("读取工具模板文件失败: %v", err)
+	}
+
+	var templates ToolTemplates
+	if err := json.Unmarshal(data, &templates); err != nil {
+		return nil, fmt.Errorf("解析工具模板文件失败: %v", err)
+	}
+
+	return templates, nil
+}
+
+// ValidateResult 校验命令执行结果，返回所有不满足的断言
+func ValidateResult(result *ToolResult, expectedOutput string, expect *ToolExpect) error {
+	var failures []string
+
+	wantExit := 0
+	if expect != nil && expect.ExitCode != nil {
+		wantExit = *expect.ExitCode
+	}
+	if result.ExitCode != wantExit {
+		failures = append(failures, fmt.Sprintf("退出码不匹配: 期望 %d, 实际 %d", wantExit, result.ExitCode))
+	}
+
+	if expectedOutput != "" && !strings.Contains(result.Stdout, expectedOutput) {
+		failures = append(failures, fmt.Sprintf("标准输出未包含期望内容: '%s'", expectedOutput))
+	}
+
+	if expect != nil {
+		if expect.StdoutContains != "" && !strings.Contains(result.Stdout, expect.StdoutContains) {
+			failures = append(failures, fmt.Sprintf("标准输出未包含: '%s'", expect.StdoutContains))
+		}
+		if expect.StderrContains != "" && !strings.Contains(result.Stderr, expect.StderrContains) {
+			failures = append(failures, fmt.Sprintf("标准错误未包含: '%s'", expect.StderrContains))
+		}
+		if msg := matchRegex("标准输出", expect.StdoutRegex, result.Stdout); msg != "" {
+			failures = append(failures, msg)
+		}
+		if msg := matchRegex("标准错误", expect.StderrRegex, result.Stderr); msg != "" {
+			failures = append(failures, msg)
+		}
+
+		lines := countLines(result.Stdout)
+		if expect.LineCount != nil && lines != *expect.LineCount {
+			failures = append(failures, fmt.Sprintf("标准输出行数不匹配: 期望 %d, 实际 %d", *expect.LineCount, lines))
+		}
+		if expect.MinLines != nil && lines < *expect.MinLines {
+			failures = append(failures, fmt.Sprintf("标准输出行数过少: 期望至少 %d, 实际 %d", *expect.MinLines, lines))
+		}
+		if expect.MaxLines != nil && lines > *expect.MaxLines {
+			failures = append(failures, fmt.Sprintf("标准输出行数过多: 期望至多 %d, 实际 %d", *expect.MaxLines, lines))
+		}
+	}
+
+	if len(failures) > 0 {
+		return fmt.Errorf("%s", strings.Join(failures, "; "))
+	}
+	return nil
+}
+
+// matchRegex 正则断言，匹配成功返回空字符串
+func matchRegex(name, pattern, text string) string {
+	if pattern == "" {
+		return ""
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return fmt.Sprintf("%s正则表达式无效 '%s': %v", name, pattern, err)
+	}
+	if !re.MatchString(text) {
+		return fmt.Sprintf("%s不匹配正则: '%s'", name, pattern)
+	}
+	return ""
+}
+
+// countLines 统计非空输出的行数（忽略末尾换行）
+func countLines(text string) int {
+	text = strings.TrimRight(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	if text == "" {
+		return 0
+	}
+	return strings.Count(text, "\n") + 1
+}
@@ -0,0 +1,123 @@
+package toolsTemplate
+
+import (
+	apisTemplate "autotest/apis-template"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadToolTemplates(t *testing.T) {
+	templates, err := LoadToolTemplates("tools.json")
+	if err != nil {
+		t.Fatalf("LoadToolTemplates 失败: %v", err)
+	}
+
+	ping, exists := templates["ping"]
+	if !exists {
+		t.Fatalf("未找到模板 'ping'")
+	}
+	if ping.Command != "ping -c 4 {target_ip}" {
+		t.Errorf("ping 模板命令错误: %s", ping.Command)
+	}
+}
+
+func TestGenerateCommand_ParamReplacement(t *testing.T) {
+	tmpl := ToolTemplate{
+		Command:        "traceroute {target_ip}",
+		ExpectedOutput: "traceroute to {target_ip}",
+		Expect:         &ToolExpect{StdoutRegex: `{target_ip}\s`},
+	}
+
+	got, err := GenerateCommand(tmpl, []apisTemplate.Param{{Key: "{target_ip}", Value: "10.0.0.1"}})
+	if err != nil {
+		t.Fatalf("GenerateCommand 出错: %v", err)
+	}
+	if got.Command != "traceroute 10.0.0.1" {
+		t.Errorf("命令替换错误: %s", got.Command)
+	}
+	if got.ExpectedOutput != "traceroute to 10.0.0.1" {
+		t.Errorf("expected_output 替换错误: %s", got.ExpectedOutput)
+	}
+	if got.Expect.StdoutRegex != `10.0.0.1\s` {
+		t.Errorf("断言替换错误: %s", got.Expect.StdoutRegex)
+	}
+	if tmpl.Expect.StdoutRegex != `{target_ip}\s` {
+		t.Errorf("原始模板被修改: %s", tmpl.Expect.StdoutRegex)
+	}
+	if _, err := GenerateCommand(tmpl, nil); err == nil || !strings.Contains(err.Error(), "{target_ip}") {
+		t.Errorf("缺少参数时应返回错误: %v", err)
+	}
+}
+
+func TestGenerateCommand_ShellQuote(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("使用 sh 语法，仅在类 Unix 系统运行")
+	}
+
+	tmpl := ToolTemplate{Command: "echo {msg}"}
+	value := `it's "ok"; echo injected`
+	got, err := GenerateCommand(tmpl, []apisTemplate.Param{{Key: "{msg}", Value: value}})
+	if err != nil {
+		t.Fatalf("GenerateCommand 出错: %v", err)
+	}
+	result, err := ExecuteCommand(got.Command, 5*time.Second)
+	if err != nil {
+		t.Fatalf("ExecuteCommand 出错: %v", err)
+	}
+	if strings.TrimSpace(result.Stdout) != value {
+		t.Errorf("参数应作为一个整体传给命令, 实际输出: %q", result.Stdout)
+	}
+}
+
+func TestExecuteCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("使用 sh 语法，仅在类 Unix 系统运行")
+	}
+
+	result, err := ExecuteCommand("echo line1; echo line2; echo oops >&2; exit 3", 5*time.Second)
+	if err != nil {
+		t.Fatalf("ExecuteCommand 出错: %v", err)
+	}
+	if result.ExitCode != 3 {
+		t.Errorf("退出码期望 3, 实际 %d", result.ExitCode)
+	}
+
+	exit3, two := 3, 2
+	expect := &ToolExpect{
+		ExitCode:       &exit3,
+		StdoutContains: "line2",
+		StderrContains: "oops",
+		StdoutRegex:    `^line\d`,
+		LineCount:      &two,
+	}
+	if err := ValidateResult(result, "line1", expect); err != nil {
+		t.Errorf("断言应通过: %v", err)
+	}
+
+	err = ValidateResult(result, "", &ToolExpect{StdoutContains: "missing", MinLines: &exit3})
+	if err == nil {
+		t.Fatal("断言应失败")
+	}
+	for _, want := range []string{"退出码不匹配", "标准输出未包含: 'missing'", "行数过少"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("错误信息缺少 '%s': %v", want, err)
+		}
+	}
+}
+
+func TestExecuteCommand_Timeout(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("使用 sh 语法，仅在类 Unix 系统运行")
+	}
+
+	start := time.Now()
+	_, err := ExecuteCommand("sleep 5", 200*time.Millisecond)
+	if err == nil || !strings.Contains(err.Error(), "超时") {
+		t.Errorf("期望超时错误, 实际: %v", err)
+	}
+	if time.Since(start) > 3*time.Second {
+		t.Errorf("超时后未及时返回: %s", time.Since(start))
+	}
+}